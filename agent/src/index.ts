@@ -1,4 +1,4 @@
-import type { IdentifyResponse, Signals } from "./types";
+import type { IdentifyOptions, IdentifyResponse, Signals } from "./types";
 
 class SignetAgent {
   private readonly performanceStart: number;
@@ -7,7 +7,10 @@ class SignetAgent {
     this.performanceStart = performance.now();
   }
 
-  async identify(apiEndpoint: string): Promise<IdentifyResponse> {
+  async identify(
+    apiEndpoint: string,
+    options: IdentifyOptions = {},
+  ): Promise<IdentifyResponse> {
     const signals = await this.collectSignals();
 
     const elapsed = performance.now() - this.performanceStart;
@@ -20,14 +23,40 @@ class SignetAgent {
     const response = await fetch(apiEndpoint, {
       method: "POST",
       headers: { "Content-Type": "application/json" },
-      body: JSON.stringify({ signals }),
+      body: JSON.stringify({
+        signals,
+        tag: options.tag,
+        linked_id: options.linkedId,
+        url: location.href,
+        referrer: document.referrer || undefined,
+        consent: options.consent,
+        session_id:
+          options.consent === "none" ? this.sessionId() : undefined,
+      }),
     });
 
     if (!response.ok) {
       throw new Error(`Identification failed: ${response.statusText}`);
     }
 
-    return response.json();
+    const result: IdentifyResponse = await response.json();
+    if (result.consent === "none") {
+      this.sessionId(result.visitor_id);
+    }
+    return result;
+  }
+
+  /** Reads or stores the session-scoped ID issued without consent. */
+  private sessionId(id?: string): string | undefined {
+    try {
+      if (id) {
+        sessionStorage.setItem("signet_session_id", id);
+        return id;
+      }
+      return sessionStorage.getItem("signet_session_id") ?? undefined;
+    } catch {
+      return undefined; // Storage blocked
+    }
   }
 
   private async collectSignals(): Promise<Signals> {
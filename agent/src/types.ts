@@ -54,9 +54,71 @@ export interface Signals {
   do_not_track?: string;
 }
 
+export interface IdentifyOptions {
+  /** Arbitrary JSON attached to the identification (max 16KB serialized). */
+  tag?: Record<string, unknown>;
+  /** Caller-side identifier, e.g. an account or order ID. */
+  linkedId?: string;
+  /** Tracking consent; the server default applies when omitted. */
+  consent?: ConsentState;
+}
+
+export type ConsentState = "none" | "analytics" | "full";
+
+export type BotVerdict = "human" | "suspected_bot" | "bad_bot" | "good_bot";
+
+export interface BotResult {
+  verdict: BotVerdict;
+  score: number;
+  reasons?: string[];
+}
+
+export interface Inconsistency {
+  code: string;
+  severity: "low" | "medium" | "high";
+}
+
+export interface GeoLocation {
+  country?: string;
+  region?: string;
+  city?: string;
+  asn?: number;
+  as_org?: string;
+}
+
+export type OptOutPolicy = "ignore" | "refuse" | "ephemeral" | "aggregate";
+
+export interface PrivacyDecision {
+  signals: ("dnt" | "gpc")[];
+  policy: OptOutPolicy;
+}
+
+export interface Uniqueness {
+  /** Visitors, this one included, that reported the exact same features. */
+  anonymity_set: number;
+  /** Percentage of visitors in larger anonymity sets. */
+  more_unique_than: number;
+}
+
 export interface IdentifyResponse {
   visitor_id: string;
   confidence: number;
   is_new: boolean;
   request_id: string;
+  bot: BotResult;
+  risk_flags?: string[];
+  tampering?: Inconsistency[];
+  ip_flags?: string[];
+  geo?: GeoLocation;
+  /** Set when the device was blocked by an erasure request; nothing was stored. */
+  blocked?: boolean;
+  consent: ConsentState;
+  /** Signal groups the entropy budget allowed, and their estimated entropy. */
+  signals_used?: string[];
+  entropy_bits?: number;
+  /** Set when the server tracks uniqueness; omitted for bots. */
+  uniqueness?: Uniqueness;
+  /** Set when the request carried Do Not Track or Global Privacy Control. */
+  privacy?: PrivacyDecision;
+  sealed_result?: string;
 }
@@ -14,10 +14,19 @@ import (
 	"github.com/iamgideonidoko/signet/internal/config"
 	"github.com/iamgideonidoko/signet/internal/handlers"
 	"github.com/iamgideonidoko/signet/internal/middleware"
+	"github.com/iamgideonidoko/signet/internal/models"
 	"github.com/iamgideonidoko/signet/internal/repository"
 	"github.com/iamgideonidoko/signet/internal/services"
+	"github.com/iamgideonidoko/signet/pkg/botdetect"
+	"github.com/iamgideonidoko/signet/pkg/budget"
 	"github.com/iamgideonidoko/signet/pkg/cache"
+	"github.com/iamgideonidoko/signet/pkg/envelope"
+	"github.com/iamgideonidoko/signet/pkg/geoip"
+	"github.com/iamgideonidoko/signet/pkg/goodbot"
+	"github.com/iamgideonidoko/signet/pkg/ipintel"
 	"github.com/iamgideonidoko/signet/pkg/logger"
+	"github.com/iamgideonidoko/signet/pkg/netutil"
+	"github.com/iamgideonidoko/signet/pkg/sealed"
 )
 
 func main() {
@@ -59,6 +68,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	if cfg.Encryption.ActiveKeyID != "" {
+		keyring, err := envelope.LoadKeyring(cfg.Encryption.Keys, cfg.Encryption.KeyFile, cfg.Encryption.ActiveKeyID)
+		if err != nil {
+			logger.Error("Failed to load signal encryption keys", map[string]any{"error": err.Error()})
+			_ = repo.Close()
+			os.Exit(1)
+		}
+		repo.EncryptSignals(keyring)
+		logger.Info("Signal encryption enabled", map[string]any{"key_id": keyring.ActiveKeyID()})
+	}
+
 	var redisCache *cache.Cache
 	err = repository.WithRetry(context.Background(), repository.DefaultRetryConfig, func() error {
 		var retryErr error
@@ -89,10 +109,124 @@ func main() {
 		}
 	}()
 
-	identService := services.NewIdentificationService(repo, redisCache, &cfg.Fingerprint)
+	serviceOpts := []services.Option{
+		services.WithBotEngine(botdetect.NewEngine(botdetect.DefaultRules(), botdetect.Config{
+			SuspectThreshold: cfg.Bot.SuspectThreshold,
+			BadThreshold:     cfg.Bot.BadThreshold,
+			Disabled:         cfg.Bot.DisabledRules,
+			Weights:          cfg.Bot.RuleWeights,
+		})),
+		services.WithOptOutPolicy(models.OptOutPolicy(cfg.Privacy.OptOutPolicy)),
+		services.WithDefaultConsent(models.ConsentState(cfg.Privacy.DefaultConsent)),
+	}
+	if cfg.Privacy.FeatureHashKey != "" {
+		serviceOpts = append(serviceOpts, services.WithFeatureHashKey([]byte(cfg.Privacy.FeatureHashKey)))
+	}
+	if cfg.Velocity.Enabled {
+		serviceOpts = append(serviceOpts, services.WithVelocityChecker(
+			services.NewVelocityChecker(redisCache, &cfg.Velocity),
+		))
+	}
+
+	if cfg.Fingerprint.Uniqueness {
+		serviceOpts = append(serviceOpts, services.WithUniquenessTracker(
+			services.NewUniquenessTracker(redisCache),
+		))
+	}
+
+	if cfg.Fingerprint.BudgetsFile != "" {
+		budgets, err := budget.Load(cfg.Fingerprint.BudgetsFile)
+		if err != nil {
+			logger.Error("Failed to load entropy budgets", map[string]any{"error": err.Error()})
+			os.Exit(1)
+		}
+		serviceOpts = append(serviceOpts, services.WithEntropyBudgets(budgets))
+		logger.Info("Loaded entropy budgets", map[string]any{
+			"origins":  len(budgets.Origins),
+			"api_keys": len(budgets.APIKeys),
+		})
+	}
+
+	if cfg.Pseudonyms.Enabled {
+		serviceOpts = append(serviceOpts, services.WithIPPseudonymizer(
+			services.NewIPPseudonymizer(redisCache, &cfg.Pseudonyms),
+		))
+	}
+
+	if cfg.Bot.GoodBotRangesDir != "" {
+		goodBots, err := goodbot.NewVerifier(cfg.Bot.GoodBotRangesDir)
+		if err != nil {
+			logger.Error("Failed to load good bot ranges", map[string]any{"error": err.Error()})
+			os.Exit(1)
+		}
+		serviceOpts = append(serviceOpts, services.WithGoodBotVerifier(goodBots))
+		logger.Info("Loaded good bot ranges", map[string]any{"prefixes": goodBots.Stats()})
+	}
+
+	if cfg.Bot.IPIntelDir != "" {
+		ipIntel, err := ipintel.NewDatabase(cfg.Bot.IPIntelDir)
+		if err != nil {
+			logger.Error("Failed to load IP intelligence", map[string]any{"error": err.Error()})
+			os.Exit(1)
+		}
+		serviceOpts = append(serviceOpts, services.WithIPIntel(ipIntel))
+		logger.Info("Loaded IP intelligence", map[string]any{"prefixes": ipIntel.Stats()})
+	}
+
+	if cfg.GeoIP.CityDBPath != "" || cfg.GeoIP.ASNDBPath != "" {
+		geoReader, err := geoip.Open(cfg.GeoIP.CityDBPath, cfg.GeoIP.ASNDBPath)
+		if err != nil {
+			logger.Error("Failed to open GeoIP databases", map[string]any{"error": err.Error()})
+			os.Exit(1)
+		}
+		defer func() {
+			if err := geoReader.Close(); err != nil {
+				logger.Error("Failed to close GeoIP databases", map[string]any{"error": err.Error()})
+			}
+		}()
+		serviceOpts = append(serviceOpts, services.WithGeoIP(geoReader))
+		logger.Info("Loaded GeoIP databases", map[string]any{"databases": geoReader.Stats()})
+	}
+
+	if cfg.Sealed.Enabled {
+		sealer, err := newSealer(&cfg.Sealed)
+		if err != nil {
+			logger.Error("Failed to initialize sealed results", map[string]any{"error": err.Error()})
+			os.Exit(1)
+		}
+		serviceOpts = append(serviceOpts, services.WithSealer(sealer))
+		logger.Info("Sealed results enabled", map[string]any{
+			"key_id":    cfg.Sealed.ActiveKeyID,
+			"encrypted": cfg.Sealed.ActiveEncryptionKeyID != "",
+		})
+	}
+
+	identService := services.NewIdentificationService(repo, redisCache, &cfg.Fingerprint, serviceOpts...)
 	logger.Info("Initialized identification service")
 
-	handler := handlers.NewHandler(identService, redisCache)
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	purger := services.NewRetentionPurger(repo, redisCache, &cfg.Retention)
+	if purger.Enabled() {
+		go purger.Run(backgroundCtx)
+		logger.Info("Retention purger started", map[string]any{
+			"raw_signals_days":      cfg.Retention.RawSignalsDays,
+			"identification_days":   cfg.Retention.IdentificationDays,
+			"inactive_visitor_days": cfg.Retention.InactiveVisitorDays,
+		})
+	}
+
+	correlations := services.NewCorrelationJob(identService, &cfg.Correlation)
+	if correlations.Enabled() {
+		go correlations.Run(backgroundCtx)
+		logger.Info("Signal correlation job started", map[string]any{
+			"interval": cfg.Correlation.Interval.String(),
+			"days":     cfg.Correlation.Days,
+		})
+	}
+
+	handler := handlers.NewHandler(identService, redisCache, cfg.Fingerprint.SubnetPrefixes)
 
 	app := fiber.New(fiber.Config{
 		DisableStartupMessage: false,
@@ -114,11 +248,18 @@ func main() {
 		},
 	})
 
+	proxyResolver, err := netutil.NewProxyResolver(cfg.Security.ClientIPHeader, cfg.Security.TrustedProxies)
+	if err != nil {
+		logger.Error("Invalid trusted proxy configuration", map[string]any{"error": err.Error()})
+		os.Exit(1)
+	}
+
 	app.Use(middleware.Recover())
+	app.Use(middleware.ResolveClientIP(proxyResolver))
 	app.Use(middleware.Logger())
 	app.Use(middleware.CORS(cfg.Security.CORSOrigins))
 
-	rateLimiter := middleware.NewRateLimiter(redisCache, &cfg.RateLimit)
+	rateLimiter := middleware.NewRateLimiter(redisCache, &cfg.RateLimit, cfg.Fingerprint.SubnetPrefixes)
 
 	app.Get("/health", handler.Health)
 	app.Get("/metrics", handler.Metrics)
@@ -130,9 +271,18 @@ func main() {
 		handler.Identify,
 	)
 
+	requireSecretKey := middleware.RequireSecretKey(cfg.Security.SecretAPIKeys)
+	v1.Get("/events/:request_id", requireSecretKey, handler.GetEvent)
+	v1.Get("/visitors/:visitor_id", requireSecretKey, handler.GetVisitor)
+	v1.Get("/visitors/:visitor_id/export", requireSecretKey, handler.ExportVisitor)
+	v1.Delete("/visitors/:visitor_id", requireSecretKey, handler.DeleteVisitor)
+	v1.Post("/admin/reload", requireSecretKey, handler.ReloadDatasets)
+
 	api := app.Group("/api")
 	api.Get("/analytics", handler.Analytics)
 	api.Get("/identifications", handler.RecentIdentifications)
+	api.Get("/entropy", handler.Entropy)
+	api.Get("/entropy/correlations", handler.Correlations)
 
 	app.Static("/agent.js", "./agent/dist/index.iife.js")
 	app.Static("/agent.js.map", "./agent/dist/index.iife.js.map")
@@ -140,9 +290,25 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
+	// SIGHUP reloads local datasets without a restart
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+
+	go func() {
+		for range hupChan {
+			stats, err := identService.ReloadDatasets()
+			if err != nil {
+				logger.Error("Failed to reload datasets", map[string]any{"error": err.Error()})
+				continue
+			}
+			logger.Info("Reloaded datasets", map[string]any{"prefixes": stats})
+		}
+	}()
+
 	go func() {
 		<-sigChan
 		logger.Info("Shutting down gracefully...")
+		stopBackground()
 
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
@@ -162,3 +328,38 @@ func main() {
 		logger.Error("Server error", map[string]any{"error": err.Error()})
 	}
 }
+
+// newSealer builds a sealer from the active signing and encryption keys.
+func newSealer(cfg *config.SealedResultsConfig) (*sealed.Sealer, error) {
+	var signingKey *sealed.Key
+	for _, spec := range cfg.SigningKeys {
+		key, err := sealed.ParseKey(spec)
+		if err != nil {
+			return nil, err
+		}
+		if key.ID == cfg.ActiveKeyID {
+			signingKey = &key
+		}
+	}
+	if signingKey == nil {
+		return nil, fmt.Errorf("active signing key %q not found", cfg.ActiveKeyID)
+	}
+
+	var encryptionKey *sealed.EncryptionKey
+	if cfg.ActiveEncryptionKeyID != "" {
+		for _, spec := range cfg.EncryptionKeys {
+			key, err := sealed.ParseEncryptionKey(spec)
+			if err != nil {
+				return nil, err
+			}
+			if key.ID == cfg.ActiveEncryptionKeyID {
+				encryptionKey = &key
+			}
+		}
+		if encryptionKey == nil {
+			return nil, fmt.Errorf("active encryption key %q not found", cfg.ActiveEncryptionKeyID)
+		}
+	}
+
+	return sealed.NewSealer(*signingKey, encryptionKey)
+}
@@ -0,0 +1,239 @@
+// Command signetctl runs maintenance tasks against the Signet database and cache.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+
+	"github.com/iamgideonidoko/signet/internal/config"
+	"github.com/iamgideonidoko/signet/internal/repository"
+	"github.com/iamgideonidoko/signet/internal/services"
+	"github.com/iamgideonidoko/signet/pkg/cache"
+	"github.com/iamgideonidoko/signet/pkg/envelope"
+	"github.com/iamgideonidoko/signet/pkg/logger"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, env *environment, args []string) error
+}
+
+var commands = []command{
+	{"recompute-trust", "Recompute every visitor's trust score", recomputeTrust},
+	{"purge", "Apply the retention policies once", purge},
+	{"export", "Export everything stored about a visitor (DSAR)", export},
+	{"rotate-keys", "Encrypt stored signals under the active master key", rotateKeys},
+	{"correlations", "Compute the signal correlation report now", correlations},
+}
+
+// environment holds the connections shared by all commands.
+type environment struct {
+	cfg     *config.Config
+	repo    *repository.Repository
+	cache   *cache.Cache
+	service *services.IdentificationService
+}
+
+func main() {
+	_ = godotenv.Load()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == os.Args[1] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("Failed to load config", map[string]any{"error": err.Error()})
+		os.Exit(1)
+	}
+	logger.SetLevel(logger.ParseLevel(cfg.Monitoring.LogLevel))
+
+	env, err := connect(cfg)
+	if err != nil {
+		logger.Error("Failed to connect", map[string]any{"error": err.Error()})
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = cmd.run(ctx, env, os.Args[2:])
+	stop()
+
+	_ = env.cache.Close()
+	_ = env.repo.Close()
+
+	if err != nil {
+		logger.Error("Command failed", map[string]any{"command": cmd.name, "error": err.Error()})
+		os.Exit(1)
+	}
+}
+
+func connect(cfg *config.Config) (*environment, error) {
+	repo, err := repository.NewRepository(cfg.Database.URL, cfg.Database.MaxConns, cfg.Database.MaxIdleConns)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Encryption.ActiveKeyID != "" {
+		keyring, err := envelope.LoadKeyring(cfg.Encryption.Keys, cfg.Encryption.KeyFile, cfg.Encryption.ActiveKeyID)
+		if err != nil {
+			_ = repo.Close()
+			return nil, err
+		}
+		repo.EncryptSignals(keyring)
+	}
+
+	redisCache, err := cache.NewCache(cfg.Redis.URL, cfg.Redis.CacheTTL)
+	if err != nil {
+		_ = repo.Close()
+		return nil, err
+	}
+
+	return &environment{
+		cfg:     cfg,
+		repo:    repo,
+		cache:   redisCache,
+		service: services.NewIdentificationService(repo, redisCache, &cfg.Fingerprint),
+	}, nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: signetctl <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", c.name, c.description)
+	}
+}
+
+func recomputeTrust(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("recompute-trust", flag.ExitOnError)
+	batchSize := fs.Int("batch-size", 500, "visitors per batch")
+	_ = fs.Parse(args)
+
+	updated, err := env.service.RecomputeTrustScores(ctx, *batchSize)
+	logger.Info("Trust score recomputation finished", map[string]any{"visitors": updated})
+	return err
+}
+
+func purge(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	batchSize := fs.Int("batch-size", env.cfg.Retention.BatchSize, "rows per batch")
+	_ = fs.Parse(args)
+
+	retention := env.cfg.Retention
+	retention.BatchSize = *batchSize
+
+	result, err := services.NewRetentionPurger(env.repo, env.cache, &retention).Purge(ctx)
+	logger.Info("Retention purge finished", map[string]any{
+		"signals_minimized":       result.SignalsMinimized,
+		"identifications_deleted": result.IdentificationsDeleted,
+		"visitors_deleted":        result.VisitorsDeleted,
+	})
+	return err
+}
+
+func rotateKeys(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("rotate-keys", flag.ExitOnError)
+	batchSize := fs.Int("batch-size", 500, "identifications per batch")
+	_ = fs.Parse(args)
+
+	if env.cfg.Encryption.ActiveKeyID == "" {
+		return errors.New("SIGNALS_ACTIVE_KEY_ID is not set")
+	}
+
+	rotated, err := env.service.RotateSignalKeys(ctx, *batchSize)
+	logger.Info("Signal key rotation finished", map[string]any{
+		"identifications": rotated,
+		"key_id":          env.cfg.Encryption.ActiveKeyID,
+	})
+	return err
+}
+
+func correlations(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("correlations", flag.ExitOnError)
+	days := fs.Int("days", env.cfg.Correlation.Days, "days of visitors to measure")
+	_ = fs.Parse(args)
+
+	if *days < 1 {
+		return errors.New("-days must be positive")
+	}
+
+	report, err := env.service.ComputeCorrelations(ctx, *days)
+	if err != nil {
+		return err
+	}
+	logger.Info("Signal correlation job finished", map[string]any{
+		"population": report.Population,
+		"pairs":      len(report.Pairs),
+		"report_id":  report.ReportID,
+	})
+	return nil
+}
+
+func export(ctx context.Context, env *environment, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "json", "json or zip")
+	output := fs.String("o", "", "output file (default stdout)")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: signetctl export [-format json|zip] [-o file] <visitor_id>")
+	}
+	visitorID, err := uuid.Parse(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid visitor ID: %w", err)
+	}
+	if *format != "json" && *format != "zip" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	// Export through a velocity-aware service so rolling windows are included.
+	service := env.service
+	if env.cfg.Velocity.Enabled {
+		service = services.NewIdentificationService(env.repo, env.cache, &env.cfg.Fingerprint,
+			services.WithVelocityChecker(services.NewVelocityChecker(env.cache, &env.cfg.Velocity)))
+	}
+
+	result, err := service.ExportVisitor(ctx, visitorID)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(filepath.Clean(*output))
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+
+	if *format == "zip" {
+		return services.WriteExportZip(out, result)
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
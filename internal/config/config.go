@@ -5,6 +5,8 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"github.com/iamgideonidoko/signet/pkg/netutil"
 )
 
 type Config struct {
@@ -13,7 +15,16 @@ type Config struct {
 	Redis       RedisConfig
 	Fingerprint FingerprintConfig
 	RateLimit   RateLimitConfig
+	Bot         BotDetectionConfig
+	Velocity    VelocityConfig
+	GeoIP       GeoIPConfig
+	Retention   RetentionConfig
+	Correlation CorrelationConfig
+	Privacy     PrivacyConfig
+	Encryption  EncryptionConfig
+	Pseudonyms  PseudonymizationConfig
 	Security    SecurityConfig
+	Sealed      SealedResultsConfig
 	Monitoring  MonitoringConfig
 }
 
@@ -34,11 +45,18 @@ type RedisConfig struct {
 	CacheTTL time.Duration
 }
 
+// FingerprintConfig tunes matching. SubnetPrefixes sets the network an IP
+// is reduced to for anonymization, candidate lookup and rate limiting.
+// BudgetsFile is a JSON entropy budget policy; every signal is used when it
+// is empty. Uniqueness annotates identifications with their anonymity set.
 type FingerprintConfig struct {
 	SimilarityThreshold float64
 	HardwareWeight      float64
 	EnvironmentWeight   float64
 	SoftwareWeight      float64
+	SubnetPrefixes      netutil.Prefixes
+	BudgetsFile         string
+	Uniqueness          bool
 }
 
 type RateLimitConfig struct {
@@ -48,9 +66,102 @@ type RateLimitConfig struct {
 	HardwareWindow     time.Duration
 }
 
+// BotDetectionConfig tunes the bot rules engine. RuleWeights overrides the
+// weight of a rule by reason code. GoodBotRangesDir holds crawler IP range
+// files; verified crawler detection is off when it is empty. IPIntelDir holds
+// Tor, VPN, proxy and datacenter range files; IP flagging is off when empty.
+type BotDetectionConfig struct {
+	SuspectThreshold float64
+	BadThreshold     float64
+	DisabledRules    []string
+	RuleWeights      map[string]float64
+	GoodBotRangesDir string
+	IPIntelDir       string
+}
+
+// VelocityConfig sets rolling-window anomaly limits. A limit of 0 disables
+// that rule; identifications per visitor are counted per minute.
+type VelocityConfig struct {
+	Enabled                     bool
+	Window                      time.Duration
+	MaxVisitorsPerSubnet        int
+	MaxSubnetsPerVisitor        int
+	MaxIdentificationsPerMinute int
+	MaxNewVisitorsPerHardware   int
+}
+
+// GeoIPConfig points at MaxMind-format databases. Geo enrichment is off when
+// both paths are empty.
+type GeoIPConfig struct {
+	CityDBPath string
+	ASNDBPath  string
+}
+
+// RetentionConfig sets how long data is kept, in days; 0 keeps it forever.
+// Raw signals are reduced to feature keys after RawSignalsDays, rows are
+// deleted after IdentificationDays, and visitors not seen for
+// InactiveVisitorDays are deleted with their history.
+type RetentionConfig struct {
+	RawSignalsDays      int
+	IdentificationDays  int
+	InactiveVisitorDays int
+	Interval            time.Duration
+	BatchSize           int
+}
+
+// CorrelationConfig schedules the signal correlation job, which measures
+// pairwise mutual information across the visitors of the last Days every
+// Interval; an Interval of 0 only runs it on demand.
+type CorrelationConfig struct {
+	Interval time.Duration
+	Days     int
+}
+
+// PrivacyConfig sets how requests with Do Not Track or Global Privacy
+// Control are handled ("ignore", "refuse", "ephemeral" or "aggregate") and
+// the consent state assumed when a request states none ("none", "analytics"
+// or "full"). FeatureHashKey keys the hashes stored under analytics consent;
+// when empty a random key is kept in Redis.
+type PrivacyConfig struct {
+	OptOutPolicy   string
+	DefaultConsent string
+	FeatureHashKey string
+}
+
+// EncryptionConfig enables envelope encryption of stored signals. Master
+// keys are "id:base64" specs of 32-byte AES keys, from Keys and from KeyFile
+// (one per line); new rows are wrapped by ActiveKeyID. Encryption is off
+// when ActiveKeyID is empty.
+type EncryptionConfig struct {
+	Keys        []string
+	KeyFile     string
+	ActiveKeyID string
+}
+
+// PseudonymizationConfig replaces stored IPs and subnets with HMACs keyed
+// by a salt that rotates every SaltRotation. Salts are kept for
+// SaltRetention after their period ends so recent visitors still match.
+type PseudonymizationConfig struct {
+	Enabled       bool
+	SaltRotation  time.Duration
+	SaltRetention time.Duration
+}
+
 type SecurityConfig struct {
 	CORSOrigins    []string
 	TrustedProxies []string
+	ClientIPHeader string
+	SecretAPIKeys  []string
+}
+
+// SealedResultsConfig controls signed identification results. Keys are
+// "id:alg:base64" specs; all listed keys verify, only the active one signs.
+type SealedResultsConfig struct {
+	Enabled               bool
+	SigningKeys           []string
+	ActiveKeyID           string
+	EncryptionKeys        []string
+	ActiveEncryptionKeyID string
 }
 
 type MonitoringConfig struct {
@@ -79,6 +190,12 @@ func Load() (*Config, error) {
 			HardwareWeight:      getEnvFloat("HARDWARE_WEIGHT", 0.8),
 			EnvironmentWeight:   getEnvFloat("ENVIRONMENT_WEIGHT", 0.5),
 			SoftwareWeight:      getEnvFloat("SOFTWARE_WEIGHT", 0.2),
+			SubnetPrefixes: netutil.Prefixes{
+				V4: getEnvInt("IPV4_SUBNET_PREFIX", netutil.DefaultPrefixes.V4),
+				V6: getEnvInt("IPV6_SUBNET_PREFIX", netutil.DefaultPrefixes.V6),
+			},
+			BudgetsFile: getEnv("ENTROPY_BUDGETS_FILE", ""),
+			Uniqueness:  getEnvBool("UNIQUENESS_ENABLED", false),
 		},
 		RateLimit: RateLimitConfig{
 			Requests:           getEnvInt("RATE_LIMIT_REQUESTS", 1000),
@@ -86,9 +203,64 @@ func Load() (*Config, error) {
 			RequestsByHardware: getEnvInt("RATE_LIMIT_BY_HARDWARE", 2000),
 			HardwareWindow:     getEnvDuration("RATE_LIMIT_HARDWARE_WINDOW", 1*time.Hour),
 		},
+		Bot: BotDetectionConfig{
+			SuspectThreshold: getEnvFloat("BOT_SUSPECT_THRESHOLD", 0.5),
+			BadThreshold:     getEnvFloat("BOT_BAD_THRESHOLD", 1.0),
+			DisabledRules:    getEnvSlice("BOT_DISABLED_RULES", []string{}),
+			RuleWeights:      getEnvFloatMap("BOT_RULE_WEIGHTS"),
+			GoodBotRangesDir: getEnv("GOOD_BOT_RANGES_DIR", ""),
+			IPIntelDir:       getEnv("IP_INTEL_DIR", ""),
+		},
+		Velocity: VelocityConfig{
+			Enabled:                     getEnvBool("VELOCITY_ENABLED", false),
+			Window:                      getEnvDuration("VELOCITY_WINDOW", 1*time.Hour),
+			MaxVisitorsPerSubnet:        getEnvInt("VELOCITY_MAX_VISITORS_PER_SUBNET", 50),
+			MaxSubnetsPerVisitor:        getEnvInt("VELOCITY_MAX_SUBNETS_PER_VISITOR", 10),
+			MaxIdentificationsPerMinute: getEnvInt("VELOCITY_MAX_IDENTIFICATIONS_PER_MINUTE", 30),
+			MaxNewVisitorsPerHardware:   getEnvInt("VELOCITY_MAX_NEW_VISITORS_PER_HARDWARE", 5),
+		},
+		GeoIP: GeoIPConfig{
+			CityDBPath: getEnv("GEOIP_CITY_DB", ""),
+			ASNDBPath:  getEnv("GEOIP_ASN_DB", ""),
+		},
+		Retention: RetentionConfig{
+			RawSignalsDays:      getEnvInt("RETENTION_RAW_SIGNALS_DAYS", 0),
+			IdentificationDays:  getEnvInt("RETENTION_IDENTIFICATION_DAYS", 0),
+			InactiveVisitorDays: getEnvInt("RETENTION_INACTIVE_VISITOR_DAYS", 0),
+			Interval:            getEnvDuration("RETENTION_INTERVAL", 1*time.Hour),
+			BatchSize:           getEnvInt("RETENTION_BATCH_SIZE", 1000),
+		},
+		Correlation: CorrelationConfig{
+			Interval: getEnvDuration("CORRELATION_INTERVAL", 24*time.Hour),
+			Days:     getEnvInt("CORRELATION_DAYS", 30),
+		},
+		Privacy: PrivacyConfig{
+			OptOutPolicy:   getEnv("OPT_OUT_POLICY", "ignore"),
+			DefaultConsent: getEnv("DEFAULT_CONSENT", "full"),
+			FeatureHashKey: getEnv("FEATURE_HASH_KEY", ""),
+		},
+		Encryption: EncryptionConfig{
+			Keys:        getEnvSlice("SIGNALS_ENCRYPTION_KEYS", []string{}),
+			KeyFile:     getEnv("SIGNALS_ENCRYPTION_KEY_FILE", ""),
+			ActiveKeyID: getEnv("SIGNALS_ACTIVE_KEY_ID", ""),
+		},
+		Pseudonyms: PseudonymizationConfig{
+			Enabled:       getEnvBool("IP_PSEUDONYMIZATION_ENABLED", false),
+			SaltRotation:  getEnvDuration("IP_SALT_ROTATION", 24*time.Hour),
+			SaltRetention: getEnvDuration("IP_SALT_RETENTION", 7*24*time.Hour),
+		},
 		Security: SecurityConfig{
 			CORSOrigins:    getEnvSlice("CORS_ORIGINS", []string{"*"}),
 			TrustedProxies: getEnvSlice("TRUSTED_PROXIES", []string{}),
+			ClientIPHeader: getEnv("CLIENT_IP_HEADER", "X-Forwarded-For"),
+			SecretAPIKeys:  getEnvSlice("SECRET_API_KEYS", []string{}),
+		},
+		Sealed: SealedResultsConfig{
+			Enabled:               getEnvBool("SEALED_RESULTS_ENABLED", false),
+			SigningKeys:           getEnvSlice("SEALED_SIGNING_KEYS", []string{}),
+			ActiveKeyID:           getEnv("SEALED_ACTIVE_KEY_ID", ""),
+			EncryptionKeys:        getEnvSlice("SEALED_ENCRYPTION_KEYS", []string{}),
+			ActiveEncryptionKeyID: getEnv("SEALED_ACTIVE_ENCRYPTION_KEY_ID", ""),
 		},
 		Monitoring: MonitoringConfig{
 			EnableMetrics: getEnvBool("ENABLE_METRICS", true),
@@ -110,6 +282,40 @@ func (c *Config) Validate() error {
 	if c.Fingerprint.SimilarityThreshold < 0 || c.Fingerprint.SimilarityThreshold > 1 {
 		return fmt.Errorf("SIMILARITY_THRESHOLD must be between 0 and 1")
 	}
+	if err := c.Fingerprint.SubnetPrefixes.Validate(); err != nil {
+		return fmt.Errorf("IPV4_SUBNET_PREFIX/IPV6_SUBNET_PREFIX: %w", err)
+	}
+	if c.Bot.SuspectThreshold > c.Bot.BadThreshold {
+		return fmt.Errorf("BOT_SUSPECT_THRESHOLD must not exceed BOT_BAD_THRESHOLD")
+	}
+	if c.Retention.RawSignalsDays < 0 || c.Retention.IdentificationDays < 0 || c.Retention.InactiveVisitorDays < 0 {
+		return fmt.Errorf("RETENTION_*_DAYS must not be negative")
+	}
+	if c.Retention.BatchSize < 1 || c.Retention.Interval <= 0 {
+		return fmt.Errorf("RETENTION_BATCH_SIZE and RETENTION_INTERVAL must be positive")
+	}
+	if c.Correlation.Interval < 0 || c.Correlation.Days < 1 {
+		return fmt.Errorf("CORRELATION_INTERVAL must not be negative and CORRELATION_DAYS must be positive")
+	}
+	switch c.Privacy.OptOutPolicy {
+	case "ignore", "refuse", "ephemeral", "aggregate":
+	default:
+		return fmt.Errorf("OPT_OUT_POLICY must be one of ignore, refuse, ephemeral or aggregate")
+	}
+	switch c.Privacy.DefaultConsent {
+	case "none", "analytics", "full":
+	default:
+		return fmt.Errorf("DEFAULT_CONSENT must be one of none, analytics or full")
+	}
+	if c.Encryption.ActiveKeyID != "" && len(c.Encryption.Keys) == 0 && c.Encryption.KeyFile == "" {
+		return fmt.Errorf("SIGNALS_ENCRYPTION_KEYS or SIGNALS_ENCRYPTION_KEY_FILE is required when SIGNALS_ACTIVE_KEY_ID is set")
+	}
+	if c.Pseudonyms.SaltRotation < time.Minute || c.Pseudonyms.SaltRetention < 0 {
+		return fmt.Errorf("IP_SALT_ROTATION must be at least 1m and IP_SALT_RETENTION must not be negative")
+	}
+	if c.Sealed.Enabled && (len(c.Sealed.SigningKeys) == 0 || c.Sealed.ActiveKeyID == "") {
+		return fmt.Errorf("SEALED_SIGNING_KEYS and SEALED_ACTIVE_KEY_ID are required when SEALED_RESULTS_ENABLED is set")
+	}
 	return nil
 }
 
@@ -171,6 +377,21 @@ func getEnvSlice(key string, defaultValue []string) []string {
 	return defaultValue
 }
 
+// getEnvFloatMap parses comma-separated "key:value" pairs, skipping malformed ones.
+func getEnvFloatMap(key string) map[string]float64 {
+	result := make(map[string]float64)
+	for _, item := range getEnvSlice(key, nil) {
+		parts := splitString(item, ":")
+		if len(parts) != 2 {
+			continue
+		}
+		if floatVal, err := strconv.ParseFloat(trimSpace(parts[1]), 64); err == nil {
+			result[trimSpace(parts[0])] = floatVal
+		}
+	}
+	return result
+}
+
 func splitAndTrim(s, sep string) []string {
 	var result []string
 	for _, item := range splitString(s, sep) {
@@ -63,4 +63,25 @@ func TestConfigValidation(t *testing.T) {
 	if cfg.Redis.URL == "" {
 		t.Error("Expected default REDIS_URL to be set")
 	}
+
+	if cfg.Velocity.Enabled {
+		t.Error("Expected velocity checks to be disabled by default")
+	}
+}
+
+func TestBotRuleWeights(t *testing.T) {
+	os.Clearenv()
+	_ = os.Setenv("BOT_RULE_WEIGHTS", "webdriver:0.4, headless_chrome:2,broken")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if len(cfg.Bot.RuleWeights) != 2 {
+		t.Fatalf("Expected 2 rule weights, got %v", cfg.Bot.RuleWeights)
+	}
+	if cfg.Bot.RuleWeights["webdriver"] != 0.4 || cfg.Bot.RuleWeights["headless_chrome"] != 2 {
+		t.Errorf("Unexpected rule weights %v", cfg.Bot.RuleWeights)
+	}
 }
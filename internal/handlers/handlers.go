@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/iamgideonidoko/signet/internal/middleware"
 	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/internal/repository"
 	"github.com/iamgideonidoko/signet/internal/services"
 	"github.com/iamgideonidoko/signet/pkg/cache"
 	"github.com/iamgideonidoko/signet/pkg/logger"
+	"github.com/iamgideonidoko/signet/pkg/netutil"
 	"github.com/iamgideonidoko/signet/pkg/similarity"
 	"github.com/iamgideonidoko/signet/pkg/validator"
 )
@@ -15,12 +22,14 @@ import (
 type Handler struct {
 	identService *services.IdentificationService
 	cache        *cache.Cache
+	subnets      netutil.Prefixes
 }
 
-func NewHandler(identService *services.IdentificationService, cache *cache.Cache) *Handler {
+func NewHandler(identService *services.IdentificationService, cache *cache.Cache, subnets netutil.Prefixes) *Handler {
 	return &Handler{
 		identService: identService,
 		cache:        cache,
+		subnets:      subnets,
 	}
 }
 
@@ -52,8 +61,20 @@ func (h *Handler) Identify(c *fiber.Ctx) error {
 		})
 	}
 
-	// Set IP address from request
-	req.IPAddress = middleware.AnonymizeIP(c.IP())
+	// Set IP address and first-party origin from request
+	req.ClientIP = middleware.ClientIP(c)
+	req.IPAddress = middleware.AnonymizeIP(req.ClientIP, h.subnets)
+	req.Origin = c.Get(fiber.HeaderOrigin)
+	req.APIKey = c.Get("Auth-API-Key")
+	req.Headers = models.RequestHeaders{
+		UserAgent:       c.Get(fiber.HeaderUserAgent),
+		AcceptLanguage:  c.Get(fiber.HeaderAcceptLanguage),
+		SecCHUA:         c.Get("Sec-CH-UA"),
+		SecCHUAPlatform: c.Get("Sec-CH-UA-Platform"),
+		SecCHUAMobile:   c.Get("Sec-CH-UA-Mobile"),
+		DNT:             c.Get("DNT"),
+		SecGPC:          c.Get("Sec-GPC"),
+	}
 
 	// Compute hardware hash and set in context for rate limiting
 	hardwareHash := similarity.ComputeHardwareHash(req.Signals)
@@ -72,8 +93,10 @@ func (h *Handler) Identify(c *fiber.Ctx) error {
 		})
 	}
 
-	// Track metrics
-	_ = h.cache.IncrementMetric(c.Context(), "total_identifications")
+	// Track metrics; verified crawlers are counted under good_bot_requests only.
+	if result.Bot.Verdict != models.VerdictGoodBot {
+		_ = h.cache.IncrementMetric(c.Context(), "total_identifications")
+	}
 
 	log.Info("Identification successful", map[string]any{
 		"visitor_id": result.VisitorID,
@@ -84,6 +107,176 @@ func (h *Handler) Identify(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(result)
 }
 
+// GetEvent handles GET /v1/events/:request_id.
+func (h *Handler) GetEvent(c *fiber.Ctx) error {
+	requestID, err := uuid.Parse(c.Params("request_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request_id",
+		})
+	}
+
+	ident, err := h.identService.GetIdentification(c.Context(), requestID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Identification not found",
+		})
+	}
+	if err != nil {
+		logger.Error("Failed to fetch identification", map[string]any{
+			"error":      err.Error(),
+			"request_id": requestID,
+		})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch identification",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(ident)
+}
+
+// GetVisitor handles GET /v1/visitors/:visitor_id.
+func (h *Handler) GetVisitor(c *fiber.Ctx) error {
+	visitorID, err := uuid.Parse(c.Params("visitor_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid visitor_id",
+		})
+	}
+
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	history, err := h.identService.GetVisitorHistory(c.Context(), visitorID, c.Query("cursor"), limit)
+	switch {
+	case errors.Is(err, services.ErrInvalidCursor):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid cursor",
+		})
+	case errors.Is(err, repository.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Visitor not found",
+		})
+	case err != nil:
+		logger.Error("Failed to fetch visitor history", map[string]any{
+			"error":      err.Error(),
+			"visitor_id": visitorID,
+		})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch visitor",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(history)
+}
+
+// ExportVisitor handles GET /v1/visitors/:visitor_id/export. The format
+// query parameter selects "json" (default) or "zip".
+func (h *Handler) ExportVisitor(c *fiber.Ctx) error {
+	visitorID, err := uuid.Parse(c.Params("visitor_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid visitor_id",
+		})
+	}
+
+	format := c.Query("format", "json")
+	if format != "json" && format != "zip" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "format must be json or zip",
+		})
+	}
+
+	export, err := h.identService.ExportVisitor(c.Context(), visitorID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Visitor not found",
+		})
+	case err != nil:
+		logger.Error("Failed to export visitor", map[string]any{
+			"error":      err.Error(),
+			"visitor_id": visitorID,
+		})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to export visitor",
+		})
+	}
+
+	if format == "json" {
+		return c.Status(fiber.StatusOK).JSON(export)
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteExportZip(&buf, export); err != nil {
+		logger.Error("Failed to write visitor export", map[string]any{
+			"error":      err.Error(),
+			"visitor_id": visitorID,
+		})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to export visitor",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Attachment(fmt.Sprintf("visitor-%s.zip", visitorID))
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// DeleteVisitor handles DELETE /v1/visitors/:visitor_id. Pass block=true to
+// stop the visitor's devices from being profiled again.
+func (h *Handler) DeleteVisitor(c *fiber.Ctx) error {
+	visitorID, err := uuid.Parse(c.Params("visitor_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid visitor_id",
+		})
+	}
+
+	erasure, err := h.identService.EraseVisitor(c.Context(), visitorID, c.Query("reason"), c.QueryBool("block"))
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Visitor not found",
+		})
+	case err != nil:
+		logger.Error("Failed to erase visitor", map[string]any{
+			"error":      err.Error(),
+			"visitor_id": visitorID,
+		})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to erase visitor",
+		})
+	}
+
+	logger.Info("Visitor erased", map[string]any{
+		"erasure_id":              erasure.ErasureID,
+		"identifications_deleted": erasure.IdentificationsDeleted,
+		"devices_blocked":         erasure.DevicesBlocked,
+	})
+
+	return c.Status(fiber.StatusOK).JSON(erasure)
+}
+
+// ReloadDatasets handles POST /v1/admin/reload.
+func (h *Handler) ReloadDatasets(c *fiber.Ctx) error {
+	stats, err := h.identService.ReloadDatasets()
+	if err != nil {
+		logger.Error("Failed to reload datasets", map[string]any{
+			"error": err.Error(),
+		})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to reload datasets",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"datasets": stats,
+	})
+}
+
 // Health handles GET /health.
 func (h *Handler) Health(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -100,13 +293,41 @@ func (h *Handler) Metrics(c *fiber.Ctx) error {
 	newVisitors, _ := h.cache.GetMetric(ctx, "new_visitors")
 	healedIdents, _ := h.cache.GetMetric(ctx, "healed_identifications")
 	cacheHits, _ := h.cache.GetMetric(ctx, "cache_hits")
+	goodBotRequests, _ := h.cache.GetMetric(ctx, "good_bot_requests")
+	velocityFlagged, _ := h.cache.GetMetric(ctx, "velocity_flagged")
+	visitorErasures, _ := h.cache.GetMetric(ctx, "visitor_erasures")
+	blockedIdents, _ := h.cache.GetMetric(ctx, "blocked_identifications")
+	signalsMinimized, _ := h.cache.GetMetric(ctx, "retention_signals_minimized")
+	identsPurged, _ := h.cache.GetMetric(ctx, "retention_identifications_deleted")
+	visitorsExpired, _ := h.cache.GetMetric(ctx, "retention_visitors_deleted")
+	optOutIgnored, _ := h.cache.GetMetric(ctx, "opt_out_ignore")
+	optOutRefused, _ := h.cache.GetMetric(ctx, "opt_out_refuse")
+	optOutEphemeral, _ := h.cache.GetMetric(ctx, "opt_out_ephemeral")
+	optOutAggregated, _ := h.cache.GetMetric(ctx, "opt_out_aggregate")
+	aggregateRequests, _ := h.cache.GetMetric(ctx, "aggregate_requests")
+	aggregateBotRequests, _ := h.cache.GetMetric(ctx, "aggregate_bot_requests")
+	sessionIdents, _ := h.cache.GetMetric(ctx, "session_identifications")
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"total_identifications":  totalIdents,
-		"new_visitors":           newVisitors,
-		"healed_identifications": healedIdents,
-		"cache_hits":             cacheHits,
-		"cache_hit_rate":         calculateRate(cacheHits, totalIdents),
+		"total_identifications":             totalIdents,
+		"new_visitors":                      newVisitors,
+		"healed_identifications":            healedIdents,
+		"cache_hits":                        cacheHits,
+		"cache_hit_rate":                    calculateRate(cacheHits, totalIdents),
+		"good_bot_requests":                 goodBotRequests,
+		"velocity_flagged":                  velocityFlagged,
+		"visitor_erasures":                  visitorErasures,
+		"blocked_identifications":           blockedIdents,
+		"retention_signals_minimized":       signalsMinimized,
+		"retention_identifications_deleted": identsPurged,
+		"retention_visitors_deleted":        visitorsExpired,
+		"opt_out_ignored":                   optOutIgnored,
+		"opt_out_refused":                   optOutRefused,
+		"opt_out_ephemeral":                 optOutEphemeral,
+		"opt_out_aggregated":                optOutAggregated,
+		"aggregate_requests":                aggregateRequests,
+		"aggregate_bot_requests":            aggregateBotRequests,
+		"session_identifications":           sessionIdents,
 	})
 }
 
@@ -124,11 +345,59 @@ func (h *Handler) Analytics(c *fiber.Ctx) error {
 		})
 	}
 
+	geo, err := h.identService.GetGeoBreakdown(c.Context(), days)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch analytics",
+		})
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"analytics": analytics,
+		"geo":       geo,
 	})
 }
 
+// Entropy handles GET /api/entropy. The population is non-bot visitors seen
+// in the last days days (default 30), optionally narrowed by origin and
+// country.
+func (h *Handler) Entropy(c *fiber.Ctx) error {
+	days := min(max(c.QueryInt("days", 30), 1), 90) // Cap at 90 days
+
+	report, err := h.identService.GetEntropy(c.Context(), models.EntropyFilter{
+		Days:    days,
+		Origin:  c.Query("origin"),
+		Country: strings.ToUpper(c.Query("country")),
+	})
+	if err != nil {
+		logger.Error("Failed to compute entropy", map[string]any{"error": err.Error()})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute entropy",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(report)
+}
+
+// Correlations handles GET /api/entropy/correlations, returning the latest
+// report of the signal correlation job.
+func (h *Handler) Correlations(c *fiber.Ctx) error {
+	report, err := h.identService.GetCorrelations(c.Context())
+	if errors.Is(err, repository.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "No correlation report computed yet",
+		})
+	}
+	if err != nil {
+		logger.Error("Failed to fetch correlation report", map[string]any{"error": err.Error()})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch correlation report",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(report)
+}
+
 // RecentIdentifications handles GET /api/identifications.
 func (h *Handler) RecentIdentifications(c *fiber.Ctx) error {
 	limit := c.QueryInt("limit", 50)
@@ -138,7 +407,13 @@ func (h *Handler) RecentIdentifications(c *fiber.Ctx) error {
 		limit = 100
 	}
 
-	identifications, err := h.identService.GetRecentIdentifications(c.Context(), limit, offset)
+	filter := models.IdentificationFilter{
+		Origin:   c.Query("origin"),
+		LinkedID: c.Query("linked_id"),
+		Tag:      parseTagFilter(c),
+	}
+
+	identifications, err := h.identService.GetRecentIdentifications(c.Context(), filter, limit, offset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch identifications",
@@ -479,7 +754,7 @@ func (h *Handler) Dashboard(c *fiber.Ctx) error {
                     return ` + "`" + `
                         <tr>
                             <td><span class="visitor-id">${i.visitor_id.substring(0, 12)}...</span></td>
-                            <td>${i.ip_address}</td>
+                            <td>${i.ip_address || (i.ip_hash || "").slice(0, 12)}</td>
                             <td><span class="confidence confidence-${confClass}">${confidence}%</span></td>
                             <td><span class="badge badge-${isNew ? 'new' : 'returning'}">${isNew ? 'NEW' : 'RETURNING'}</span></td>
                             <td><span class="timestamp">${timestamp}</span></td>
@@ -506,6 +781,24 @@ func (h *Handler) Dashboard(c *fiber.Ctx) error {
 	return c.SendString(html)
 }
 
+// parseTagFilter reads repeated ?tag=key:value query parameters.
+func parseTagFilter(c *fiber.Ctx) map[string]string {
+	values := c.Context().QueryArgs().PeekMulti("tag")
+	if len(values) == 0 {
+		return nil
+	}
+
+	tags := make(map[string]string, len(values))
+	for _, raw := range values {
+		key, value, ok := strings.Cut(string(raw), ":")
+		if !ok || key == "" {
+			continue
+		}
+		tags[key] = value
+	}
+	return tags
+}
+
 func calculateRate(numerator, denominator int64) float64 {
 	if denominator == 0 {
 		return 0.0
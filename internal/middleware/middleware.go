@@ -1,32 +1,39 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/iamgideonidoko/signet/internal/config"
 	"github.com/iamgideonidoko/signet/pkg/cache"
+	"github.com/iamgideonidoko/signet/pkg/netutil"
 )
 
 type RateLimiter struct {
-	cache  *cache.Cache
-	config *config.RateLimitConfig
+	cache   *cache.Cache
+	config  *config.RateLimitConfig
+	buckets netutil.Prefixes
 }
 
-func NewRateLimiter(cache *cache.Cache, config *config.RateLimitConfig) *RateLimiter {
+// NewRateLimiter limits clients per subnets prefix, the same network IPs
+// are anonymized and matched by, since one IPv6 host usually controls a
+// whole prefix. An IPv4 prefix of 32 limits per address.
+func NewRateLimiter(cache *cache.Cache, config *config.RateLimitConfig, subnets netutil.Prefixes) *RateLimiter {
 	return &RateLimiter{
-		cache:  cache,
-		config: config,
+		cache:   cache,
+		config:  config,
+		buckets: subnets,
 	}
 }
 
 // LimitByIP rate limits requests by IP address.
 func (rl *RateLimiter) LimitByIP() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ip := c.IP()
-		identifier := fmt.Sprintf("ip:%s", ip)
+		identifier := fmt.Sprintf("ip:%s", rl.buckets.Subnet(ClientIP(c)))
 
 		allowed, err := rl.cache.CheckRateLimit(
 			c.Context(),
@@ -82,6 +89,59 @@ func (rl *RateLimiter) LimitByHardwareHash() fiber.Handler {
 	}
 }
 
+// clientIPKey is the Locals key holding the resolved client IP.
+const clientIPKey = "client_ip"
+
+// ResolveClientIP stores the client IP found behind trusted proxies for
+// ClientIP. It must run before anything that reads the client IP.
+func ResolveClientIP(resolver *netutil.ProxyResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var values []string
+		for _, v := range c.Request().Header.PeekAll(resolver.Header()) {
+			values = append(values, string(v))
+		}
+
+		remote, _ := netip.AddrFromSlice(c.Context().RemoteIP())
+		if client := resolver.Resolve(remote, values); client.IsValid() {
+			c.Locals(clientIPKey, client.String())
+		}
+		return c.Next()
+	}
+}
+
+// ClientIP returns the IP resolved by ResolveClientIP, falling back to the
+// peer address.
+func ClientIP(c *fiber.Ctx) string {
+	if ip, ok := c.Locals(clientIPKey).(string); ok {
+		return ip
+	}
+	return c.IP()
+}
+
+// RequireSecretKey guards server-to-server endpoints. The key is read from the
+// Auth-API-Key header or an "Authorization: Bearer" header. With no keys
+// configured every request is rejected.
+func RequireSecretKey(keys []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		provided := c.Get("Auth-API-Key")
+		if provided == "" {
+			provided = strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		}
+
+		if provided != "" {
+			for _, key := range keys {
+				if subtle.ConstantTimeCompare([]byte(provided), []byte(key)) == 1 {
+					return c.Next()
+				}
+			}
+		}
+
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid or missing secret API key",
+		})
+	}
+}
+
 func CORS(origins []string) fiber.Handler {
 	allowedOrigins := make(map[string]bool)
 	for _, origin := range origins {
@@ -114,7 +174,7 @@ func Logger() fiber.Handler {
 
 		fmt.Printf("[%s] %s %s - %d (%v) - IP: %s\n",
 			start.Format("2006-01-02 15:04:05"),
-			c.Method(), c.Path(), c.Response().StatusCode(), duration, c.IP(),
+			c.Method(), c.Path(), c.Response().StatusCode(), duration, ClientIP(c),
 		)
 
 		return err
@@ -135,11 +195,8 @@ func Recover() fiber.Handler {
 	}
 }
 
-// AnonymizeIP removes the last octet for GDPR compliance.
-func AnonymizeIP(ip string) string {
-	parts := strings.Split(ip, ".")
-	if len(parts) == 4 {
-		return fmt.Sprintf("%s.%s.%s.0", parts[0], parts[1], parts[2])
-	}
-	return ip
+// AnonymizeIP zeroes the host bits of ip for GDPR compliance, keeping only
+// the configured IPv4 or IPv6 prefix.
+func AnonymizeIP(ip string, prefixes netutil.Prefixes) string {
+	return prefixes.Anonymize(ip)
 }
@@ -15,19 +15,92 @@ type Visitor struct {
 	FirstSeenIP *string   `json:"first_seen_ip,omitempty" db:"first_seen_ip"`
 	LastSeenIP  *string   `json:"last_seen_ip,omitempty" db:"last_seen_ip"`
 	VisitCount  int       `json:"visit_count" db:"visit_count"`
+
+	// Keyed hashes stored instead of the IPs under pseudonymization.
+	FirstSeenIPHash *string `json:"first_seen_ip_hash,omitempty" db:"first_seen_ip_hash"`
+	LastSeenIPHash  *string `json:"last_seen_ip_hash,omitempty" db:"last_seen_ip_hash"`
+}
+
+// Erasure is the audit record of a right-to-erasure request.
+type Erasure struct {
+	ErasureID              uuid.UUID `json:"erasure_id" db:"erasure_id"`
+	VisitorID              uuid.UUID `json:"visitor_id" db:"visitor_id"`
+	Reason                 *string   `json:"reason,omitempty" db:"reason"`
+	IdentificationsDeleted int       `json:"identifications_deleted" db:"identifications_deleted"`
+	DevicesBlocked         int       `json:"devices_blocked" db:"devices_blocked"`
+	ErasedAt               time.Time `json:"erased_at" db:"erased_at"`
+	CacheKeysDeleted       int       `json:"cache_keys_deleted" db:"-"`
 }
 
 // Identification represents a single fingerprint submission.
 type Identification struct {
-	RequestID       uuid.UUID `json:"request_id" db:"request_id"`
-	VisitorID       uuid.UUID `json:"visitor_id" db:"visitor_id"`
-	IPAddress       string    `json:"ip_address" db:"ip_address"`
-	UserAgent       *string   `json:"user_agent,omitempty" db:"user_agent"`
-	Signals         Signals   `json:"signals" db:"signals"`
-	ConfidenceScore float64   `json:"confidence_score" db:"confidence_score"`
-	CreatedAt       time.Time `json:"created_at" db:"created_at"`
-	HardwareHash    string    `json:"hardware_hash" db:"hardware_hash"`
-	IsBot           bool      `json:"is_bot" db:"is_bot"`
+	RequestID       uuid.UUID       `json:"request_id" db:"request_id"`
+	VisitorID       uuid.UUID       `json:"visitor_id" db:"visitor_id"`
+	IPAddress       string          `json:"ip_address,omitempty" db:"ip_address"`
+	IPSubnet        string          `json:"ip_subnet,omitempty" db:"ip_subnet"`
+	IPHash          string          `json:"ip_hash,omitempty" db:"ip_hash"`               // Set instead of IPAddress under pseudonymization
+	IPSubnetHash    string          `json:"ip_subnet_hash,omitempty" db:"ip_subnet_hash"` // Set instead of IPSubnet under pseudonymization
+	UserAgent       *string         `json:"user_agent,omitempty" db:"user_agent"`
+	Signals         Signals         `json:"signals" db:"signals"` // Zero once minimized by retention
+	Features        []string        `json:"features,omitempty" db:"features"`
+	ConfidenceScore float64         `json:"confidence_score" db:"confidence_score"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	HardwareHash    string          `json:"hardware_hash" db:"hardware_hash"`
+	IsBot           bool            `json:"is_bot" db:"is_bot"`
+	BotVerdict      BotVerdict      `json:"bot_verdict" db:"bot_verdict"`
+	BotScore        float64         `json:"bot_score" db:"bot_score"`
+	BotReasons      []string        `json:"bot_reasons,omitempty" db:"bot_reasons"`
+	RiskFlags       []string        `json:"risk_flags,omitempty" db:"risk_flags"`
+	Tampering       []Inconsistency `json:"tampering,omitempty" db:"tamper_flags"`
+	IPFlags         []string        `json:"ip_flags,omitempty" db:"ip_flags"`
+	Geo             *GeoLocation    `json:"geo,omitempty" db:"geo"`
+	Consent         ConsentState    `json:"consent" db:"consent"`
+	Uniqueness      *Uniqueness     `json:"uniqueness,omitempty" db:"-"`
+
+	// request metadata
+	Tag      map[string]any `json:"tag,omitempty" db:"tag"`
+	LinkedID *string        `json:"linked_id,omitempty" db:"linked_id"`
+	URL      *string        `json:"url,omitempty" db:"url"`
+	Referrer *string        `json:"referrer,omitempty" db:"referrer"`
+	Origin   *string        `json:"origin,omitempty" db:"origin"`
+}
+
+// BotVerdict classifies how likely a request is to be automated.
+type BotVerdict string
+
+const (
+	VerdictHuman        BotVerdict = "human"
+	VerdictSuspectedBot BotVerdict = "suspected_bot"
+	VerdictBadBot       BotVerdict = "bad_bot"
+	VerdictGoodBot      BotVerdict = "good_bot"
+)
+
+// IsBot reports whether the verdict should count as automated traffic.
+func (v BotVerdict) IsBot() bool {
+	return v == VerdictSuspectedBot || v == VerdictBadBot
+}
+
+// BotResult is the scored outcome of bot detection.
+type BotResult struct {
+	Verdict BotVerdict `json:"verdict"`
+	Score   float64    `json:"score"`
+	Reasons []string   `json:"reasons,omitempty"`
+	Crawler string     `json:"crawler,omitempty"` // Set for verified good bots
+}
+
+// Severity grades how strongly an inconsistency indicates tampering.
+type Severity string
+
+const (
+	SeverityLow    Severity = "low"
+	SeverityMedium Severity = "medium"
+	SeverityHigh   Severity = "high"
+)
+
+// Inconsistency is an impossible combination of signals.
+type Inconsistency struct {
+	Code     string   `json:"code"`
+	Severity Severity `json:"severity"`
 }
 
 type Signals struct {
@@ -82,18 +155,226 @@ type Signals struct {
 	DoNotTrack      string   `json:"do_not_track,omitempty"`
 }
 
+// ConsentState is the tracking consent a visitor has given.
+type ConsentState string
+
+const (
+	ConsentNone      ConsentState = "none"      // Session-scoped ID, nothing stored or matched
+	ConsentAnalytics ConsentState = "analytics" // Matched and stored as hashed feature keys only
+	ConsentFull      ConsentState = "full"      // Raw signals stored
+)
+
+// OptOutPolicy is how requests carrying Do Not Track or Global Privacy
+// Control are handled.
+type OptOutPolicy string
+
+const (
+	OptOutIgnore    OptOutPolicy = "ignore"    // Identify as usual
+	OptOutRefuse    OptOutPolicy = "refuse"    // Answer without a visitor ID
+	OptOutEphemeral OptOutPolicy = "ephemeral" // Issue a random, unstored visitor ID
+	OptOutAggregate OptOutPolicy = "aggregate" // Only count the request
+)
+
+// Opt-out signals a request can carry.
+const (
+	OptOutSignalDNT = "dnt"
+	OptOutSignalGPC = "gpc"
+)
+
+// PrivacyDecision reports how an opted-out request was handled.
+type PrivacyDecision struct {
+	Signals []string     `json:"signals"`
+	Policy  OptOutPolicy `json:"policy"`
+}
+
 // IdentifyRequest is the incoming fingerprint payload.
 type IdentifyRequest struct {
 	Signals   Signals `json:"signals" validate:"required"`
-	IPAddress string  `json:"-"` // Populated from request context
+	IPAddress string  `json:"-"` // Populated from request context, anonymized
+	ClientIP  string  `json:"-"` // Full client IP for in-memory lookups; never persisted
+	APIKey    string  `json:"-"` // Populated from the Auth-API-Key header, selects an entropy budget
+
+	// Consent defaults to the configured state when empty. SessionID is the
+	// ID issued earlier in the session to a request without consent.
+	Consent   ConsentState `json:"consent,omitempty"`
+	SessionID string       `json:"session_id,omitempty"`
+
+	// Optional caller-supplied metadata, persisted with the identification.
+	Tag      map[string]any `json:"tag,omitempty"`
+	LinkedID string         `json:"linked_id,omitempty"`
+	URL      string         `json:"url,omitempty"`
+	Referrer string         `json:"referrer,omitempty"`
+	Origin   string         `json:"-"` // Populated from the Origin header
+
+	Headers RequestHeaders `json:"-"` // Populated from the HTTP request
+}
+
+// RequestHeaders are the HTTP headers checked against the reported signals.
+type RequestHeaders struct {
+	UserAgent       string
+	AcceptLanguage  string
+	SecCHUA         string
+	SecCHUAPlatform string
+	SecCHUAMobile   string
+	DNT             string
+	SecGPC          string
+}
+
+// IdentificationFilter narrows identification queries by request metadata.
+type IdentificationFilter struct {
+	Origin   string
+	LinkedID string
+	Tag      map[string]string
 }
 
 // IdentifyResponse is returned to the client.
 type IdentifyResponse struct {
-	VisitorID  uuid.UUID `json:"visitor_id"`
-	Confidence float64   `json:"confidence"`
-	IsNew      bool      `json:"is_new"`
-	RequestID  uuid.UUID `json:"request_id"`
+	VisitorID  uuid.UUID       `json:"visitor_id"`
+	Confidence float64         `json:"confidence"`
+	IsNew      bool            `json:"is_new"`
+	RequestID  uuid.UUID       `json:"request_id"`
+	Bot        BotResult       `json:"bot"`
+	RiskFlags  []string        `json:"risk_flags,omitempty"`
+	Tampering  []Inconsistency `json:"tampering,omitempty"`
+	IPFlags    []string        `json:"ip_flags,omitempty"`
+	Geo        *GeoLocation    `json:"geo,omitempty"`
+	TrustScore *float64        `json:"trust_score,omitempty"`
+	Consent    ConsentState    `json:"consent"`
+	Uniqueness *Uniqueness     `json:"uniqueness,omitempty"`
+
+	// SignalsUsed lists the signal groups the entropy budget allowed, and
+	// EntropyBits their estimated entropy.
+	SignalsUsed []string `json:"signals_used,omitempty"`
+	EntropyBits float64  `json:"entropy_bits,omitempty"`
+
+	// Blocked is set for devices blocked by an erasure request; nothing
+	// about the request was stored.
+	Blocked bool `json:"blocked,omitempty"`
+
+	// Privacy is set when the request opted out of tracking.
+	Privacy *PrivacyDecision `json:"privacy,omitempty"`
+
+	// SealedResult is a signed token of this result for server-side verification.
+	SealedResult string `json:"sealed_result,omitempty"`
+}
+
+// VisitorHistory is a visitor record with a page of its identifications and
+// the distinct networks and browsers it has been seen from.
+type VisitorHistory struct {
+	Visitor          Visitor          `json:"visitor"`
+	Identifications  []Identification `json:"identifications"`
+	NextCursor       string           `json:"next_cursor,omitempty"`
+	Subnets          []string         `json:"subnets"`
+	Browsers         []string         `json:"browsers"`
+	OperatingSystems []string         `json:"operating_systems"`
+}
+
+// Uniqueness places a feature vector within the population of visitors.
+// AnonymitySet counts the visitors, this one included, that reported the
+// exact same features; MoreUniqueThan is the percentage of visitors in
+// larger anonymity sets.
+type Uniqueness struct {
+	AnonymitySet   int64   `json:"anonymity_set"`
+	MoreUniqueThan float64 `json:"more_unique_than"`
+}
+
+// GeoLocation is the coarse location and network owner of a client IP.
+type GeoLocation struct {
+	Country string `json:"country,omitempty"` // ISO 3166-1 alpha-2
+	Region  string `json:"region,omitempty"`  // ISO 3166-2 subdivision code
+	City    string `json:"city,omitempty"`
+	ASN     uint   `json:"asn,omitempty"`
+	ASOrg   string `json:"as_org,omitempty"`
+}
+
+// GeoBreakdown counts recent identifications by country and by network.
+type GeoBreakdown struct {
+	Countries []BreakdownEntry `json:"countries"`
+	ASNs      []BreakdownEntry `json:"asns"`
+}
+
+// BreakdownEntry is one bucket of a breakdown.
+type BreakdownEntry struct {
+	Key      string `json:"key" db:"key"`
+	Name     string `json:"name,omitempty" db:"name"`
+	Requests int    `json:"requests" db:"requests"`
+	Visitors int    `json:"visitors" db:"visitors"`
+}
+
+// VisitorExport is everything stored about a visitor, for data subject
+// access requests.
+type VisitorExport struct {
+	ExportedAt      time.Time        `json:"exported_at"`
+	Visitor         Visitor          `json:"visitor"`
+	Identifications []Identification `json:"identifications"`
+	LinkedIDs       []string         `json:"linked_ids"`
+	CacheEntries    []CacheEntry     `json:"cache_entries"`
+}
+
+// CacheEntry is a Redis entry that refers to a visitor.
+type CacheEntry struct {
+	Key       string   `json:"key"`
+	Values    []string `json:"values"`
+	ExpiresIn int64    `json:"expires_in_seconds,omitempty"`
+}
+
+// EntropyFilter selects the population entropy is measured over: non-bot
+// visitors seen in the last Days days, optionally from one origin or
+// country.
+type EntropyFilter struct {
+	Days    int
+	Origin  string
+	Country string
+}
+
+// EntropyReport is how much identifying information each signal carries
+// across a population, in bits.
+type EntropyReport struct {
+	Days       int             `json:"days"`
+	Origin     string          `json:"origin,omitempty"`
+	Country    string          `json:"country,omitempty"`
+	Population int64           `json:"population"` // Distinct visitors, one identification each
+	Signals    []SignalEntropy `json:"signals"`
+
+	// TotalBits sums the per-signal entropies, an upper bound that assumes
+	// independent signals. JointBits is the entropy of whole feature vectors.
+	TotalBits       float64 `json:"total_bits"`
+	JointBits       float64 `json:"joint_bits"`
+	NormalizedJoint float64 `json:"normalized_joint"`
+}
+
+// SignalEntropy is the entropy of one signal. Normalized divides Bits by
+// log₂ of the population size.
+type SignalEntropy struct {
+	Signal     string  `json:"signal"`
+	Bits       float64 `json:"bits"`
+	Normalized float64 `json:"normalized"`
+	Distinct   int     `json:"distinct_values"`
+}
+
+// CorrelationReport is the joint entropy and mutual information of every
+// pair of signals across the visitors seen within Days, as computed by the
+// correlation job. Pairs with high redundancy carry overlapping information,
+// so their Weights count the same evidence twice in similarity scoring.
+type CorrelationReport struct {
+	ReportID   uuid.UUID           `json:"report_id"`
+	ComputedAt time.Time           `json:"computed_at"`
+	Days       int                 `json:"days"`
+	Population int64               `json:"population"`
+	Weights    map[string]float64  `json:"weights"` // Similarity weight of each signal
+	Pairs      []SignalCorrelation `json:"pairs"`   // Most redundant first
+}
+
+// SignalCorrelation is the shared information of two signals, in bits.
+// Redundancy divides MutualInformation by the smaller of XBits and YBits.
+type SignalCorrelation struct {
+	X                 string  `json:"x"`
+	Y                 string  `json:"y"`
+	XBits             float64 `json:"x_bits"`
+	YBits             float64 `json:"y_bits"`
+	JointBits         float64 `json:"joint_bits"`
+	MutualInformation float64 `json:"mutual_information"`
+	Redundancy        float64 `json:"redundancy"`
 }
 
 // VisitorAnalytics represents aggregated metrics.
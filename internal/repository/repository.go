@@ -2,20 +2,26 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/envelope"
 	"github.com/iamgideonidoko/signet/pkg/logger"
+	"github.com/iamgideonidoko/signet/pkg/trust"
 )
 
 type Repository struct {
-	db *sqlx.DB
+	db         *sqlx.DB
+	signalKeys *envelope.Keyring
 }
 
 func NewRepository(dsn string, maxConns, maxIdleConns int) (*Repository, error) {
@@ -31,26 +37,37 @@ func NewRepository(dsn string, maxConns, maxIdleConns int) (*Repository, error)
 	return &Repository{db: db}, nil
 }
 
-// CreateVisitor creates a new visitor record.
-func (r *Repository) CreateVisitor(ctx context.Context, ipAddress string) (*models.Visitor, error) {
+// EncryptSignals stores the raw signals of new identifications encrypted
+// under keyring, and decrypts stored ones with it.
+func (r *Repository) EncryptSignals(keyring *envelope.Keyring) {
+	r.signalKeys = keyring
+}
+
+// CreateVisitor creates a new visitor record seen first from ipAddress or,
+// under pseudonymization, from the IP hashed to ipHash. Either may be empty.
+func (r *Repository) CreateVisitor(ctx context.Context, ipAddress, ipHash string) (*models.Visitor, error) {
 	visitor := &models.Visitor{
-		VisitorID:   uuid.New(),
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-		TrustScore:  1.0,
-		FirstSeenIP: &ipAddress,
-		LastSeenIP:  &ipAddress,
-		VisitCount:  1,
+		VisitorID:       uuid.New(),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		TrustScore:      trust.NewVisitorScore,
+		FirstSeenIP:     nullableString(ipAddress),
+		LastSeenIP:      nullableString(ipAddress),
+		VisitCount:      1,
+		FirstSeenIPHash: nullableString(ipHash),
+		LastSeenIPHash:  nullableString(ipHash),
 	}
 
 	query := `
-		INSERT INTO visitors (visitor_id, created_at, updated_at, trust_score, first_seen_ip, last_seen_ip, visit_count)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO visitors (visitor_id, created_at, updated_at, trust_score, first_seen_ip, last_seen_ip, visit_count,
+			first_seen_ip_hash, last_seen_ip_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
 		visitor.VisitorID, visitor.CreatedAt, visitor.UpdatedAt,
 		visitor.TrustScore, visitor.FirstSeenIP, visitor.LastSeenIP, visitor.VisitCount,
+		visitor.FirstSeenIPHash, visitor.LastSeenIPHash,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create visitor: %w", err)
@@ -65,28 +82,99 @@ func (r *Repository) GetVisitor(ctx context.Context, visitorID uuid.UUID) (*mode
 	query := `SELECT * FROM visitors WHERE visitor_id = $1`
 
 	if err := r.db.GetContext(ctx, &visitor, query, visitorID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
 		return nil, fmt.Errorf("failed to get visitor: %w", err)
 	}
 
 	return &visitor, nil
 }
 
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// ErrNotFound is returned when a requested record does not exist.
+var ErrNotFound = errors.New("not found")
+
+// identificationColumns lists the identification columns in insert and scan order.
+const identificationColumns = `request_id, visitor_id, ip_address, user_agent, signals, confidence_score,
+	created_at, hardware_hash, is_bot, bot_verdict, bot_score, bot_reasons, risk_flags,
+	tag, linked_id, url, referrer, origin, tamper_flags, ip_flags, geo, ip_subnet, features, consent,
+	signals_ciphertext, signals_data_key, signals_key_id, ip_hash, ip_subnet_hash,
+	anonymity_set, uniqueness_percentile`
+
 // CreateIdentification stores a new fingerprint identification.
 func (r *Repository) CreateIdentification(ctx context.Context, ident *models.Identification) error {
-	signalsJSON, err := json.Marshal(ident.Signals)
-	if err != nil {
-		return fmt.Errorf("failed to marshal signals: %w", err)
+	var signalsJSON any                // NULL when consent only covers hashed feature keys
+	var ciphertext, dataKey, keyID any // Set instead of signalsJSON when encrypting
+	if ident.Consent != models.ConsentAnalytics {
+		b, err := json.Marshal(ident.Signals)
+		if err != nil {
+			return fmt.Errorf("failed to marshal signals: %w", err)
+		}
+		if r.signalKeys == nil {
+			signalsJSON = b
+		} else {
+			sealed, err := r.signalKeys.Seal(b, ident.RequestID[:])
+			if err != nil {
+				return fmt.Errorf("failed to encrypt signals: %w", err)
+			}
+			ciphertext, dataKey, keyID = sealed.Ciphertext, sealed.DataKey, sealed.KeyID
+		}
+	}
+
+	var tagJSON any // NULL unless a tag was supplied
+	if len(ident.Tag) > 0 {
+		b, err := json.Marshal(ident.Tag)
+		if err != nil {
+			return fmt.Errorf("failed to marshal tag: %w", err)
+		}
+		tagJSON = b
+	}
+
+	var tamperJSON any // NULL when the signals are consistent
+	if len(ident.Tampering) > 0 {
+		b, err := json.Marshal(ident.Tampering)
+		if err != nil {
+			return fmt.Errorf("failed to marshal tamper flags: %w", err)
+		}
+		tamperJSON = b
+	}
+
+	var geoJSON any // NULL when no GeoIP database knows the address
+	if ident.Geo != nil {
+		b, err := json.Marshal(ident.Geo)
+		if err != nil {
+			return fmt.Errorf("failed to marshal geo: %w", err)
+		}
+		geoJSON = b
+	}
+
+	var anonymitySet, percentile any // NULL unless uniqueness is tracked
+	if ident.Uniqueness != nil {
+		anonymitySet, percentile = ident.Uniqueness.AnonymitySet, ident.Uniqueness.MoreUniqueThan
 	}
 
 	query := `
 		INSERT INTO identifications 
-		(request_id, visitor_id, ip_address, user_agent, signals, confidence_score, created_at, hardware_hash, is_bot)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		(` + identificationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31)
 	`
 
-	_, err = r.db.ExecContext(ctx, query,
-		ident.RequestID, ident.VisitorID, ident.IPAddress, ident.UserAgent,
+	_, err := r.db.ExecContext(ctx, query,
+		ident.RequestID, ident.VisitorID, nullableString(ident.IPAddress), ident.UserAgent,
 		signalsJSON, ident.ConfidenceScore, ident.CreatedAt, ident.HardwareHash, ident.IsBot,
+		ident.BotVerdict, ident.BotScore, pq.Array(ident.BotReasons), pq.Array(ident.RiskFlags),
+		tagJSON, ident.LinkedID, ident.URL, ident.Referrer, ident.Origin, tamperJSON,
+		pq.Array(ident.IPFlags), geoJSON, nullableString(ident.IPSubnet),
+		pq.Array(ident.Features), ident.Consent,
+		ciphertext, dataKey, keyID, nullableString(ident.IPHash), nullableString(ident.IPSubnetHash),
+		anonymitySet, percentile,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create identification: %w", err)
@@ -95,49 +183,174 @@ func (r *Repository) CreateIdentification(ctx context.Context, ident *models.Ide
 	return nil
 }
 
-// FindSimilarVisitors finds visitors with similar fingerprints in the same IP subnet.
-func (r *Repository) FindSimilarVisitors(ctx context.Context, ipSubnet string, limit int) ([]models.Identification, error) {
+// FindSimilarVisitors finds visitors with similar fingerprints in the same IP
+// subnet, stored either in plain form or as one of subnetHashes.
+func (r *Repository) FindSimilarVisitors(ctx context.Context, ipSubnet string, subnetHashes []string, limit int) ([]models.Identification, error) {
 	query := `
-		SELECT DISTINCT ON (visitor_id) *
+		SELECT DISTINCT ON (visitor_id) ` + identificationColumns + `
 		FROM identifications
-		WHERE ip_subnet = $1::cidr
+		WHERE ip_subnet = NULLIF($1, '')::cidr OR ip_subnet_hash = ANY($2)
 		ORDER BY visitor_id, created_at DESC
-		LIMIT $2
+		LIMIT $3
 	`
 
-	var identifications []models.Identification
-	rows, err := r.db.QueryxContext(ctx, query, ipSubnet, limit)
+	rows, err := r.db.QueryxContext(ctx, query, ipSubnet, pq.Array(subnetHashes), limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find similar visitors: %w", err)
 	}
-	defer func() {
-		if err := rows.Close(); err != nil {
-			logger.Warn("Failed to close database rows", map[string]any{
-				"error": err.Error(),
-			})
-		}
-	}()
 
-	for rows.Next() {
-		var ident models.Identification
-		var signalsJSON []byte
+	return r.scanIdentifications(rows)
+}
 
-		err := rows.Scan(
-			&ident.RequestID, &ident.VisitorID, &ident.IPAddress, &ident.UserAgent,
-			&signalsJSON, &ident.ConfidenceScore, &ident.CreatedAt, &ident.HardwareHash, &ident.IsBot,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan identification: %w", err)
-		}
+// GetIdentification retrieves a single identification by request ID.
+func (r *Repository) GetIdentification(ctx context.Context, requestID uuid.UUID) (*models.Identification, error) {
+	query := `SELECT ` + identificationColumns + ` FROM identifications WHERE request_id = $1`
 
-		if err := json.Unmarshal(signalsJSON, &ident.Signals); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal signals: %w", err)
-		}
+	rows, err := r.db.QueryxContext(ctx, query, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get identification: %w", err)
+	}
 
-		identifications = append(identifications, ident)
+	identifications, err := r.scanIdentifications(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(identifications) == 0 {
+		return nil, ErrNotFound
 	}
 
-	return identifications, nil
+	return &identifications[0], nil
+}
+
+// GetVisitorIdentifications retrieves a visitor's identifications newest first.
+// When before is non-nil only rows strictly older than (before, beforeID) are
+// returned, which makes the pair usable as a keyset cursor.
+func (r *Repository) GetVisitorIdentifications(
+	ctx context.Context,
+	visitorID uuid.UUID,
+	before *time.Time,
+	beforeID uuid.UUID,
+	limit int,
+) ([]models.Identification, error) {
+	query := `
+		SELECT ` + identificationColumns + `
+		FROM identifications
+		WHERE visitor_id = $1
+		  AND ($2::timestamp IS NULL OR (created_at, request_id) < ($2, $3))
+		ORDER BY created_at DESC, request_id DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryxContext(ctx, query, visitorID, before, beforeID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get visitor identifications: %w", err)
+	}
+
+	return r.scanIdentifications(rows)
+}
+
+// GetVisitorSubnets lists the distinct IP subnets, or their hashes under
+// pseudonymization, a visitor was seen from.
+func (r *Repository) GetVisitorSubnets(ctx context.Context, visitorID uuid.UUID, limit int) ([]string, error) {
+	query := `
+		SELECT COALESCE(ip_subnet::text, ip_subnet_hash, '')
+		FROM identifications
+		WHERE visitor_id = $1
+		GROUP BY 1
+		ORDER BY MAX(created_at) DESC
+		LIMIT $2
+	`
+
+	var subnets []string
+	if err := r.db.SelectContext(ctx, &subnets, query, visitorID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get visitor subnets: %w", err)
+	}
+
+	return subnets, nil
+}
+
+// GetVisitorUserAgents lists the distinct user agents a visitor reported.
+func (r *Repository) GetVisitorUserAgents(ctx context.Context, visitorID uuid.UUID, limit int) ([]string, error) {
+	query := `
+		SELECT DISTINCT COALESCE(signals->>'user_agent', user_agent)
+		FROM identifications
+		WHERE visitor_id = $1 AND COALESCE(signals->>'user_agent', user_agent) IS NOT NULL
+		LIMIT $2
+	`
+
+	var userAgents []string
+	if err := r.db.SelectContext(ctx, &userAgents, query, visitorID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get visitor user agents: %w", err)
+	}
+
+	return userAgents, nil
+}
+
+// trustWindow bounds how many recent identifications feed a trust score.
+const trustWindow = 500
+
+// GetTrustInputs aggregates a visitor's recent identifications for scoring.
+// Identifications with tamper flags, or whose bot reasons overlap
+// tamperReasons, count as tampered.
+func (r *Repository) GetTrustInputs(ctx context.Context, visitorID uuid.UUID, tamperReasons []string) (*trust.Inputs, error) {
+	query := `
+		SELECT
+			v.created_at,
+			v.visit_count,
+			COUNT(i.request_id) AS identifications,
+			COUNT(*) FILTER (WHERE i.bot_verdict IN ('suspected_bot', 'bad_bot')) AS bots,
+			COUNT(*) FILTER (WHERE cardinality(i.risk_flags) > 0) AS flagged,
+			COUNT(*) FILTER (WHERE i.tamper_flags IS NOT NULL OR i.bot_reasons && $2) AS tampered,
+			COUNT(DISTINCT NULLIF(i.hardware_hash, '')) AS hardware_hashes
+		FROM visitors v
+		LEFT JOIN LATERAL (
+			SELECT request_id, bot_verdict, bot_reasons, risk_flags, tamper_flags, hardware_hash
+			FROM identifications
+			WHERE visitor_id = v.visitor_id
+			ORDER BY created_at DESC
+			LIMIT $3
+		) i ON TRUE
+		WHERE v.visitor_id = $1
+		GROUP BY v.visitor_id
+	`
+
+	var createdAt time.Time
+	var in trust.Inputs
+	err := r.db.QueryRowxContext(ctx, query, visitorID, pq.Array(tamperReasons), trustWindow).Scan(
+		&createdAt, &in.VisitCount, &in.Identifications, &in.BotCount,
+		&in.FlaggedCount, &in.TamperCount, &in.HardwareHashes,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trust inputs: %w", err)
+	}
+
+	in.VisitorAge = time.Since(createdAt)
+	return &in, nil
+}
+
+// UpdateTrustScore stores a visitor's trust score.
+func (r *Repository) UpdateTrustScore(ctx context.Context, visitorID uuid.UUID, score float64) error {
+	query := `UPDATE visitors SET trust_score = $2 WHERE visitor_id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, visitorID, score); err != nil {
+		return fmt.Errorf("failed to update trust score: %w", err)
+	}
+	return nil
+}
+
+// ListVisitorIDs pages through visitor IDs in ascending order, starting after
+// the given ID (uuid.Nil for the first page).
+func (r *Repository) ListVisitorIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	query := `SELECT visitor_id FROM visitors WHERE visitor_id > $1 ORDER BY visitor_id LIMIT $2`
+
+	var ids []uuid.UUID
+	if err := r.db.SelectContext(ctx, &ids, query, after, limit); err != nil {
+		return nil, fmt.Errorf("failed to list visitors: %w", err)
+	}
+	return ids, nil
 }
 
 // UpdateVisitorSignals updates a visitor's signals with new data (self-healing).
@@ -169,19 +382,189 @@ func (r *Repository) GetAnalytics(ctx context.Context, days int) ([]models.Visit
 	return analytics, nil
 }
 
-// GetRecentIdentifications retrieves recent identifications with pagination.
-func (r *Repository) GetRecentIdentifications(ctx context.Context, limit, offset int) ([]models.Identification, error) {
+// geoBreakdownLimit caps the buckets returned per breakdown.
+const geoBreakdownLimit = 20
+
+// GetGeoBreakdown counts the last days of identifications by country and ASN.
+func (r *Repository) GetGeoBreakdown(ctx context.Context, days int) (*models.GeoBreakdown, error) {
+	countries := `
+		SELECT
+			geo->>'country' AS key,
+			'' AS name,
+			COUNT(*) AS requests,
+			COUNT(DISTINCT visitor_id) AS visitors
+		FROM identifications
+		WHERE created_at >= CURRENT_DATE - $1::integer
+			AND geo->>'country' IS NOT NULL
+		GROUP BY 1
+		ORDER BY requests DESC
+		LIMIT $2
+	`
+
+	asns := `
+		SELECT
+			'AS' || (geo->>'asn') AS key,
+			COALESCE(MAX(geo->>'as_org'), '') AS name,
+			COUNT(*) AS requests,
+			COUNT(DISTINCT visitor_id) AS visitors
+		FROM identifications
+		WHERE created_at >= CURRENT_DATE - $1::integer
+			AND geo->>'asn' IS NOT NULL
+		GROUP BY 1
+		ORDER BY requests DESC
+		LIMIT $2
+	`
+
+	breakdown := models.GeoBreakdown{
+		Countries: []models.BreakdownEntry{},
+		ASNs:      []models.BreakdownEntry{},
+	}
+	if err := r.db.SelectContext(ctx, &breakdown.Countries, countries, days, geoBreakdownLimit); err != nil {
+		return nil, fmt.Errorf("failed to get country breakdown: %w", err)
+	}
+	if err := r.db.SelectContext(ctx, &breakdown.ASNs, asns, days, geoBreakdownLimit); err != nil {
+		return nil, fmt.Errorf("failed to get ASN breakdown: %w", err)
+	}
+
+	return &breakdown, nil
+}
+
+// entropyPopulation selects the latest feature keys of each non-bot visitor
+// matching an EntropyFilter ($1 days, $2 origin, $3 country).
+const entropyPopulation = `
+	WITH population AS (
+		SELECT DISTINCT ON (visitor_id) features
+		FROM identifications
+		WHERE created_at >= CURRENT_DATE - $1::integer
+			AND NOT is_bot
+			AND features IS NOT NULL
+			AND ($2 = '' OR origin = $2)
+			AND ($3 = '' OR geo->>'country' = $3)
+		ORDER BY visitor_id, created_at DESC
+	)
+`
+
+// EachPopulationFeatures calls fn with the feature keys of each visitor
+// selected by filter, streaming rows so the population need not fit in
+// memory.
+func (r *Repository) EachPopulationFeatures(ctx context.Context, filter models.EntropyFilter, fn func([]string)) error {
+	query := entropyPopulation + `SELECT features FROM population`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.Days, filter.Origin, filter.Country)
+	if err != nil {
+		return fmt.Errorf("failed to read feature vectors: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Warn("Failed to close database rows", map[string]any{
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	for rows.Next() {
+		var features []string
+		if err := rows.Scan(pq.Array(&features)); err != nil {
+			return fmt.Errorf("failed to scan feature vector: %w", err)
+		}
+		fn(features)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate feature vectors: %w", err)
+	}
+	return nil
+}
+
+// CreateCorrelationReport stores a run of the correlation job.
+func (r *Repository) CreateCorrelationReport(ctx context.Context, report *models.CorrelationReport) error {
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal correlation report: %w", err)
+	}
+
 	query := `
-		SELECT * FROM identifications
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		INSERT INTO correlation_reports (report_id, computed_at, days, population, report)
+		VALUES ($1, $2, $3, $4, $5)
 	`
+	_, err = r.db.ExecContext(ctx, query,
+		report.ReportID, report.ComputedAt, report.Days, report.Population, reportJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create correlation report: %w", err)
+	}
+	return nil
+}
 
-	var identifications []models.Identification
-	rows, err := r.db.QueryxContext(ctx, query, limit, offset)
+// GetLatestCorrelationReport returns the most recent correlation report.
+func (r *Repository) GetLatestCorrelationReport(ctx context.Context) (*models.CorrelationReport, error) {
+	query := `SELECT report FROM correlation_reports ORDER BY computed_at DESC LIMIT 1`
+
+	var reportJSON []byte
+	if err := r.db.GetContext(ctx, &reportJSON, query); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get correlation report: %w", err)
+	}
+
+	var report models.CorrelationReport
+	if err := json.Unmarshal(reportJSON, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal correlation report: %w", err)
+	}
+	return &report, nil
+}
+
+// GetRecentIdentifications retrieves recent identifications with pagination,
+// optionally narrowed by request metadata.
+func (r *Repository) GetRecentIdentifications(
+	ctx context.Context,
+	filter models.IdentificationFilter,
+	limit, offset int,
+) ([]models.Identification, error) {
+	var conditions []string
+	var args []any
+
+	if filter.Origin != "" {
+		args = append(args, filter.Origin)
+		conditions = append(conditions, fmt.Sprintf("origin = $%d", len(args)))
+	}
+	if filter.LinkedID != "" {
+		args = append(args, filter.LinkedID)
+		conditions = append(conditions, fmt.Sprintf("linked_id = $%d", len(args)))
+	}
+	if len(filter.Tag) > 0 {
+		tagJSON, err := json.Marshal(filter.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal tag filter: %w", err)
+		}
+		args = append(args, tagJSON)
+		conditions = append(conditions, fmt.Sprintf("tag @> $%d::jsonb", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT %s FROM identifications
+		%s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, identificationColumns, where, len(args)-1, len(args))
+
+	rows, err := r.db.QueryxContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recent identifications: %w", err)
 	}
+
+	return r.scanIdentifications(rows)
+}
+
+// scanIdentifications reads identificationColumns rows, decrypting their
+// signals, and closes them.
+func (r *Repository) scanIdentifications(rows *sqlx.Rows) ([]models.Identification, error) {
 	defer func() {
 		if err := rows.Close(); err != nil {
 			logger.Warn("Failed to close database rows", map[string]any{
@@ -190,28 +573,465 @@ func (r *Repository) GetRecentIdentifications(ctx context.Context, limit, offset
 		}
 	}()
 
+	var identifications []models.Identification
 	for rows.Next() {
 		var ident models.Identification
-		var signalsJSON []byte
+		var signalsJSON, tagJSON, tamperJSON, geoJSON []byte
+		var sealed envelope.Sealed
+		var keyID, ipAddress, ipSubnet, ipHash, subnetHash sql.NullString
+		var anonymitySet sql.NullInt64
+		var percentile sql.NullFloat64
 
 		err := rows.Scan(
-			&ident.RequestID, &ident.VisitorID, &ident.IPAddress, &ident.UserAgent,
+			&ident.RequestID, &ident.VisitorID, &ipAddress, &ident.UserAgent,
 			&signalsJSON, &ident.ConfidenceScore, &ident.CreatedAt, &ident.HardwareHash, &ident.IsBot,
+			&ident.BotVerdict, &ident.BotScore, pq.Array(&ident.BotReasons), pq.Array(&ident.RiskFlags),
+			&tagJSON, &ident.LinkedID, &ident.URL, &ident.Referrer, &ident.Origin, &tamperJSON,
+			pq.Array(&ident.IPFlags), &geoJSON,
+			&ipSubnet, pq.Array(&ident.Features), &ident.Consent,
+			&sealed.Ciphertext, &sealed.DataKey, &keyID, &ipHash, &subnetHash,
+			&anonymitySet, &percentile,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan identification: %w", err)
 		}
+		ident.IPAddress, ident.IPSubnet = ipAddress.String, ipSubnet.String
+		ident.IPHash, ident.IPSubnetHash = ipHash.String, subnetHash.String
+		if anonymitySet.Valid {
+			ident.Uniqueness = &models.Uniqueness{
+				AnonymitySet:   anonymitySet.Int64,
+				MoreUniqueThan: percentile.Float64,
+			}
+		}
+
+		if len(sealed.Ciphertext) > 0 {
+			if r.signalKeys == nil {
+				return nil, errors.New("failed to decrypt signals: no signal encryption keys configured")
+			}
+			sealed.KeyID = keyID.String
+			signalsJSON, err = r.signalKeys.Open(sealed, ident.RequestID[:])
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt signals: %w", err)
+			}
+		}
 
-		if err := json.Unmarshal(signalsJSON, &ident.Signals); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal signals: %w", err)
+		if len(signalsJSON) > 0 {
+			if err := json.Unmarshal(signalsJSON, &ident.Signals); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal signals: %w", err)
+			}
+		}
+		if len(tagJSON) > 0 {
+			if err := json.Unmarshal(tagJSON, &ident.Tag); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal tag: %w", err)
+			}
+		}
+		if len(tamperJSON) > 0 {
+			if err := json.Unmarshal(tamperJSON, &ident.Tampering); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal tamper flags: %w", err)
+			}
+		}
+		if len(geoJSON) > 0 {
+			ident.Geo = &models.GeoLocation{}
+			if err := json.Unmarshal(geoJSON, ident.Geo); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal geo: %w", err)
+			}
 		}
 
 		identifications = append(identifications, ident)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate identifications: %w", err)
+	}
+
 	return identifications, nil
 }
 
+// MinimizeSignals drops the raw signals, plain or encrypted, and user agent
+// of up to limit identifications created before cutoff, keeping their
+// feature keys. Rows without feature keys are left alone, as matching would
+// have nothing left to use. Rows locked by other transactions are skipped
+// rather than waited on.
+func (r *Repository) MinimizeSignals(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		UPDATE identifications
+		SET signals = NULL, signals_ciphertext = NULL, signals_data_key = NULL, signals_key_id = NULL,
+			user_agent = NULL
+		WHERE request_id IN (
+			SELECT request_id FROM identifications
+			WHERE (signals IS NOT NULL OR signals_ciphertext IS NOT NULL) AND created_at < $1
+				AND features IS NOT NULL
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	return r.execCount(ctx, "minimize signals", query, cutoff, limit)
+}
+
+// GetUnfeaturedIdentifications returns up to limit identifications created
+// before cutoff that still hold raw signals but no feature keys, which
+// MinimizeSignals skips until their keys are backfilled.
+func (r *Repository) GetUnfeaturedIdentifications(ctx context.Context, cutoff time.Time, limit int) ([]models.Identification, error) {
+	query := `
+		SELECT ` + identificationColumns + `
+		FROM identifications
+		WHERE (signals IS NOT NULL OR signals_ciphertext IS NOT NULL) AND features IS NULL AND created_at < $1
+		ORDER BY created_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryxContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unfeatured identifications: %w", err)
+	}
+
+	return r.scanIdentifications(rows)
+}
+
+// SetFeatures stores the feature keys of identifications by request ID.
+func (r *Repository) SetFeatures(ctx context.Context, features map[uuid.UUID][]string) error {
+	if len(features) == 0 {
+		return nil
+	}
+
+	type row struct {
+		RequestID uuid.UUID `json:"request_id"`
+		Features  []string  `json:"features"`
+	}
+	rows := make([]row, 0, len(features))
+	for id, keys := range features {
+		rows = append(rows, row{RequestID: id, Features: keys})
+	}
+	rowsJSON, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("failed to marshal features: %w", err)
+	}
+
+	query := `
+		UPDATE identifications i
+		SET features = ARRAY(SELECT jsonb_array_elements_text(v.features))
+		FROM jsonb_to_recordset($1::jsonb) AS v(request_id uuid, features jsonb)
+		WHERE i.request_id = v.request_id
+	`
+	if _, err := r.db.ExecContext(ctx, query, rowsJSON); err != nil {
+		return fmt.Errorf("failed to set features: %w", err)
+	}
+	return nil
+}
+
+// SetUniqueness stores the uniqueness of an identification.
+func (r *Repository) SetUniqueness(ctx context.Context, requestID uuid.UUID, uniqueness models.Uniqueness) error {
+	query := `
+		UPDATE identifications
+		SET anonymity_set = $2, uniqueness_percentile = $3
+		WHERE request_id = $1
+	`
+	if _, err := r.db.ExecContext(ctx, query, requestID, uniqueness.AnonymitySet, uniqueness.MoreUniqueThan); err != nil {
+		return fmt.Errorf("failed to set uniqueness: %w", err)
+	}
+	return nil
+}
+
+// DeleteIdentificationsBefore deletes up to limit identifications created
+// before cutoff.
+func (r *Repository) DeleteIdentificationsBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM identifications
+		WHERE request_id IN (
+			SELECT request_id FROM identifications
+			WHERE created_at < $1
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	return r.execCount(ctx, "delete identifications", query, cutoff, limit)
+}
+
+// DeletedVisitor is a visitor removed from the database, with the hardware
+// hashes and subnets of its identifications, for clearing derived caches.
+type DeletedVisitor struct {
+	VisitorID      uuid.UUID
+	HardwareHashes []string
+	Subnets        []string
+}
+
+// DeleteInactiveVisitors deletes up to limit visitors not seen since cutoff,
+// together with their remaining identifications.
+func (r *Repository) DeleteInactiveVisitors(ctx context.Context, cutoff time.Time, limit int) ([]DeletedVisitor, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin inactive visitor deletion: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var visitorIDs []uuid.UUID
+	err = tx.SelectContext(ctx, &visitorIDs, `
+		SELECT visitor_id FROM visitors
+		WHERE updated_at < $1
+		ORDER BY updated_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select inactive visitors: %w", err)
+	}
+	if len(visitorIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM identifications
+		WHERE visitor_id = ANY($1)
+		RETURNING visitor_id, hardware_hash, COALESCE(ip_subnet::text, ip_subnet_hash, '')
+	`, pq.Array(visitorIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete identifications: %w", err)
+	}
+	deleted, _, err := scanDeletedVisitors(rows, visitorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM visitors WHERE visitor_id = ANY($1)`, pq.Array(visitorIDs)); err != nil {
+		return nil, fmt.Errorf("failed to delete inactive visitors: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit inactive visitor deletion: %w", err)
+	}
+	return deleted, nil
+}
+
+// scanDeletedVisitors groups deleted (visitor_id, hardware_hash, subnet)
+// rows by visitor, one entry per ID in visitorIDs, and counts the rows.
+// Empty hashes, stored when an entropy budget cleared hardware signals,
+// identify no device and are left out.
+func scanDeletedVisitors(rows *sql.Rows, visitorIDs []uuid.UUID) ([]DeletedVisitor, int, error) {
+	defer func() { _ = rows.Close() }()
+
+	deleted := make([]DeletedVisitor, len(visitorIDs))
+	index := make(map[uuid.UUID]int, len(visitorIDs))
+	for i, id := range visitorIDs {
+		deleted[i].VisitorID = id
+		index[id] = i
+	}
+
+	seen := make(map[string]bool)
+	count := 0
+	for rows.Next() {
+		var visitorID uuid.UUID
+		var hash, subnet string
+		if err := rows.Scan(&visitorID, &hash, &subnet); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan deleted identification: %w", err)
+		}
+		count++
+
+		i, ok := index[visitorID]
+		if !ok {
+			continue
+		}
+		v := &deleted[i]
+		if key := visitorID.String() + "|hw|" + hash; hash != "" && !seen[key] {
+			seen[key] = true
+			v.HardwareHashes = append(v.HardwareHashes, hash)
+		}
+		if key := visitorID.String() + "|net|" + subnet; subnet != "" && !seen[key] {
+			seen[key] = true
+			v.Subnets = append(v.Subnets, subnet)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to delete identifications: %w", err)
+	}
+	return deleted, count, nil
+}
+
+// RotateSignalKeys brings up to limit identifications after the given
+// request ID under the active signal encryption key: plaintext signals are
+// encrypted and data keys wrapped by older master keys are rewrapped. Rows
+// locked by other transactions are waited for rather than skipped, so no
+// row is passed over. It returns how many rows were rotated and the last
+// request ID, to continue from.
+func (r *Repository) RotateSignalKeys(ctx context.Context, after uuid.UUID, limit int) (int, uuid.UUID, error) {
+	if r.signalKeys == nil {
+		return 0, after, errors.New("signal encryption is not configured")
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, after, fmt.Errorf("failed to begin key rotation: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT request_id, signals, signals_ciphertext, signals_data_key, COALESCE(signals_key_id, '')
+		FROM identifications
+		WHERE request_id > $1 AND (signals IS NOT NULL OR signals_key_id <> $2)
+		ORDER BY request_id
+		LIMIT $3
+		FOR UPDATE
+	`, after, r.signalKeys.ActiveKeyID(), limit)
+	if err != nil {
+		return 0, after, fmt.Errorf("failed to select identifications for key rotation: %w", err)
+	}
+
+	type row struct {
+		requestID uuid.UUID
+		signals   []byte
+		sealed    envelope.Sealed
+	}
+	var batch []row
+	for rows.Next() {
+		var rw row
+		if err := rows.Scan(&rw.requestID, &rw.signals, &rw.sealed.Ciphertext, &rw.sealed.DataKey, &rw.sealed.KeyID); err != nil {
+			_ = rows.Close()
+			return 0, after, fmt.Errorf("failed to scan identification for key rotation: %w", err)
+		}
+		batch = append(batch, rw)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, after, fmt.Errorf("failed to select identifications for key rotation: %w", err)
+	}
+
+	for _, rw := range batch {
+		var sealed envelope.Sealed
+		if rw.signals != nil {
+			sealed, err = r.signalKeys.Seal(rw.signals, rw.requestID[:])
+		} else {
+			sealed, err = r.signalKeys.Rewrap(rw.sealed)
+		}
+		if err != nil {
+			return 0, after, fmt.Errorf("failed to re-encrypt signals of %s: %w", rw.requestID, err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE identifications
+			SET signals = NULL, signals_ciphertext = $2, signals_data_key = $3, signals_key_id = $4
+			WHERE request_id = $1
+		`, rw.requestID, sealed.Ciphertext, sealed.DataKey, sealed.KeyID)
+		if err != nil {
+			return 0, after, fmt.Errorf("failed to update signals of %s: %w", rw.requestID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, after, fmt.Errorf("failed to commit key rotation: %w", err)
+	}
+
+	if len(batch) > 0 {
+		after = batch[len(batch)-1].requestID
+	}
+	return len(batch), after, nil
+}
+
+func (r *Repository) execCount(ctx context.Context, op, query string, args ...any) (int64, error) {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to %s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to %s: %w", op, err)
+	}
+	return n, nil
+}
+
+// ErasedVisitor is the outcome of EraseVisitor. HardwareHashes and Subnets
+// are those of the deleted identifications, for clearing derived caches.
+type ErasedVisitor struct {
+	Erasure        models.Erasure
+	HardwareHashes []string
+	Subnets        []string
+}
+
+// EraseVisitor deletes a visitor and all its identifications, including
+// their linked IDs and tags, and records the erasure in the audit trail in
+// one transaction. With block set, the visitor's hardware hashes are added
+// to blocked_devices so the device is not profiled again.
+func (r *Repository) EraseVisitor(ctx context.Context, visitorID uuid.UUID, reason *string, block bool) (*ErasedVisitor, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin erasure: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `
+		DELETE FROM identifications
+		WHERE visitor_id = $1
+		RETURNING visitor_id, hardware_hash, COALESCE(ip_subnet::text, ip_subnet_hash, '')
+	`, visitorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete identifications: %w", err)
+	}
+	visitors, deleted, err := scanDeletedVisitors(rows, []uuid.UUID{visitorID})
+	if err != nil {
+		return nil, err
+	}
+	result := &ErasedVisitor{
+		HardwareHashes: visitors[0].HardwareHashes,
+		Subnets:        visitors[0].Subnets,
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM visitors WHERE visitor_id = $1`, visitorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete visitor: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+
+	erasure := models.Erasure{
+		ErasureID:              uuid.New(),
+		VisitorID:              visitorID,
+		Reason:                 reason,
+		IdentificationsDeleted: deleted,
+		ErasedAt:               time.Now(),
+	}
+	if block {
+		erasure.DevicesBlocked = len(result.HardwareHashes)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO visitor_erasures
+		(erasure_id, visitor_id, reason, identifications_deleted, devices_blocked, erased_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, erasure.ErasureID, erasure.VisitorID, erasure.Reason,
+		erasure.IdentificationsDeleted, erasure.DevicesBlocked, erasure.ErasedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record erasure: %w", err)
+	}
+
+	if block && len(result.HardwareHashes) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO blocked_devices (hardware_hash, erasure_id)
+			SELECT unnest($1::text[]), $2
+			ON CONFLICT (hardware_hash) DO NOTHING
+		`, pq.Array(result.HardwareHashes), erasure.ErasureID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to block devices: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit erasure: %w", err)
+	}
+
+	result.Erasure = erasure
+	return result, nil
+}
+
+// IsDeviceBlocked reports whether a hardware hash was blocked by an erasure.
+func (r *Repository) IsDeviceBlocked(ctx context.Context, hardwareHash string) (bool, error) {
+	var blocked bool
+	query := `SELECT EXISTS (SELECT 1 FROM blocked_devices WHERE hardware_hash = $1)`
+	if err := r.db.GetContext(ctx, &blocked, query, hardwareHash); err != nil {
+		return false, fmt.Errorf("failed to check blocked device: %w", err)
+	}
+	return blocked, nil
+}
+
 // Close closes the database connection.
 func (r *Repository) Close() error {
 	return r.db.Close()
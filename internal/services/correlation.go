@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iamgideonidoko/signet/internal/config"
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/entropy"
+	"github.com/iamgideonidoko/signet/pkg/logger"
+)
+
+// ComputeCorrelations measures the joint entropy and mutual information of
+// every pair of weighted signals across the visitors seen in the last days,
+// and stores the result as the latest correlation report. Feature keys are
+// hashed like those stored under analytics consent before they are counted.
+func (s *IdentificationService) ComputeCorrelations(ctx context.Context, days int) (*models.CorrelationReport, error) {
+	weights := s.calculator.CategoryWeights()
+	signals := make([]string, 0, len(weights))
+	for signal := range weights {
+		signals = append(signals, signal)
+	}
+	correlator := entropy.NewCorrelator(signals)
+
+	filter := models.EntropyFilter{Days: days}
+	err := s.eachPopulationKeys(ctx, filter, func(features []string) {
+		values := make(map[string]string, len(features))
+		for _, key := range features {
+			signal, value, _ := strings.Cut(key, ":")
+			values[signal] = value
+		}
+		correlator.Add(values)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := correlator.Pairs()
+	report := &models.CorrelationReport{
+		ReportID:   uuid.New(),
+		ComputedAt: time.Now(),
+		Days:       days,
+		Population: correlator.Members(),
+		Weights:    weights,
+		Pairs:      make([]models.SignalCorrelation, len(pairs)),
+	}
+	for i, p := range pairs {
+		report.Pairs[i] = models.SignalCorrelation{
+			X:                 p.X,
+			Y:                 p.Y,
+			XBits:             p.HX,
+			YBits:             p.HY,
+			JointBits:         p.HXY,
+			MutualInformation: p.MutualInformation,
+			Redundancy:        p.Redundancy,
+		}
+	}
+
+	if err := s.repo.CreateCorrelationReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetCorrelations returns the latest correlation report.
+func (s *IdentificationService) GetCorrelations(ctx context.Context) (*models.CorrelationReport, error) {
+	return s.repo.GetLatestCorrelationReport(ctx)
+}
+
+// CorrelationJob recomputes the correlation report on a schedule.
+type CorrelationJob struct {
+	service *IdentificationService
+	config  *config.CorrelationConfig
+}
+
+func NewCorrelationJob(service *IdentificationService, cfg *config.CorrelationConfig) *CorrelationJob {
+	return &CorrelationJob{
+		service: service,
+		config:  cfg,
+	}
+}
+
+// Enabled reports whether the job runs on a schedule.
+func (j *CorrelationJob) Enabled() bool {
+	return j.config.Interval > 0
+}
+
+// Run computes a report once per Interval until ctx is cancelled.
+func (j *CorrelationJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := j.service.ComputeCorrelations(ctx, j.config.Days)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Signal correlation job failed", map[string]any{"error": err.Error()})
+		}
+		if err == nil {
+			logger.Info("Signal correlation job finished", map[string]any{
+				"population": report.Population,
+				"pairs":      len(report.Pairs),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package services
+
+import "fmt"
+
+// ReloadDatasets re-reads the local IP range and GeoIP datasets and returns
+// per-dataset stats. A dataset that fails to reload keeps its
+// previous ranges.
+func (s *IdentificationService) ReloadDatasets() (map[string]map[string]int, error) {
+	stats := make(map[string]map[string]int)
+
+	if s.goodBots != nil {
+		if err := s.goodBots.Reload(); err != nil {
+			return nil, fmt.Errorf("failed to reload good bot ranges: %w", err)
+		}
+		stats["good_bots"] = s.goodBots.Stats()
+	}
+
+	if s.ipIntel != nil {
+		if err := s.ipIntel.Reload(); err != nil {
+			return nil, fmt.Errorf("failed to reload IP intelligence: %w", err)
+		}
+		stats["ip_intel"] = s.ipIntel.Stats()
+	}
+
+	if s.geoIP != nil {
+		if err := s.geoIP.Reload(); err != nil {
+			return nil, fmt.Errorf("failed to reload GeoIP databases: %w", err)
+		}
+		stats["geoip"] = s.geoIP.Stats()
+	}
+
+	return stats, nil
+}
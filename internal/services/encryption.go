@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/iamgideonidoko/signet/pkg/logger"
+)
+
+// rotateBatch rotates up to limit identifications after a request ID and
+// returns how many it rotated and the last request ID.
+type rotateBatch func(ctx context.Context, after uuid.UUID, limit int) (int, uuid.UUID, error)
+
+// RotateSignalKeys walks all identifications in batches, encrypting
+// plaintext signals and rewrapping data keys under the active master key.
+// It runs alongside live traffic, so passes repeat until one finds nothing
+// left to rotate; only then may the old master key be retired.
+func (s *IdentificationService) RotateSignalKeys(ctx context.Context, batchSize int) (int, error) {
+	return rotateSignalKeys(ctx, batchSize, s.repo.RotateSignalKeys)
+}
+
+func rotateSignalKeys(ctx context.Context, batchSize int, rotate rotateBatch) (int, error) {
+	rotated := 0
+
+	for {
+		pass := 0
+		after := uuid.Nil
+		for {
+			n, last, err := rotate(ctx, after, batchSize)
+			if err != nil {
+				return rotated, err
+			}
+			pass += n
+			rotated += n
+
+			if n < batchSize {
+				break
+			}
+			after = last
+
+			logger.Info("Rotated signal encryption keys", map[string]any{"identifications": rotated})
+		}
+
+		if pass == 0 {
+			return rotated, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return rotated, err
+		}
+	}
+}
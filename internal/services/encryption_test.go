@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeRotation keeps request IDs still needing rotation, sorted like the
+// repository pages them.
+type fakeRotation struct {
+	pending []uuid.UUID
+	locked  map[uuid.UUID]bool      // Busy during the first pass only
+	behind  map[uuid.UUID]uuid.UUID // Rows written behind the cursor once it passes the key
+	passes  int
+}
+
+func (f *fakeRotation) rotate(_ context.Context, after uuid.UUID, limit int) (int, uuid.UUID, error) {
+	if after == uuid.Nil {
+		f.passes++
+	}
+	for trigger, id := range f.behind {
+		if compareUUID(after, trigger) >= 0 {
+			f.pending = append(f.pending, id)
+			slices.SortFunc(f.pending, compareUUID)
+			delete(f.behind, trigger)
+		}
+	}
+
+	var batch []uuid.UUID
+	for _, id := range f.pending {
+		if compareUUID(id, after) <= 0 || (f.passes == 1 && f.locked[id]) {
+			continue
+		}
+		batch = append(batch, id)
+		if len(batch) == limit {
+			break
+		}
+	}
+	f.pending = slices.DeleteFunc(f.pending, func(id uuid.UUID) bool { return slices.Contains(batch, id) })
+
+	if len(batch) == 0 {
+		return 0, after, nil
+	}
+	return len(batch), batch[len(batch)-1], nil
+}
+
+func compareUUID(a, b uuid.UUID) int {
+	return slices.Compare(a[:], b[:])
+}
+
+func testUUID(b byte) uuid.UUID {
+	var id uuid.UUID
+	id[0] = b
+	return id
+}
+
+func TestRotateSignalKeys_RotatesRowsMissedByAPass(t *testing.T) {
+	var pending []uuid.UUID
+	for b := byte(1); b <= 10; b++ {
+		pending = append(pending, testUUID(b*10))
+	}
+
+	fake := &fakeRotation{
+		pending: pending,
+		locked:  map[uuid.UUID]bool{testUUID(30): true, testUUID(70): true},
+		behind:  map[uuid.UUID]uuid.UUID{testUUID(80): testUUID(5)},
+	}
+
+	rotated, err := rotateSignalKeys(context.Background(), 3, fake.rotate)
+	if err != nil {
+		t.Fatalf("rotateSignalKeys() failed: %v", err)
+	}
+	if rotated != 11 {
+		t.Errorf("Expected 11 rows rotated, got %d", rotated)
+	}
+	if len(fake.pending) != 0 || len(fake.behind) != 0 {
+		t.Errorf("Rows left unrotated: %v", fake.pending)
+	}
+	if fake.passes < 2 {
+		t.Errorf("Expected a confirming pass, got %d passes", fake.passes)
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/entropy"
+	"github.com/iamgideonidoko/signet/pkg/similarity"
+)
+
+// GetEntropy measures the Shannon entropy of each signal, and of whole
+// feature vectors, across the visitors selected by filter. A visitor that
+// did not report a signal counts towards a shared "missing" value.
+func (s *IdentificationService) GetEntropy(ctx context.Context, filter models.EntropyFilter) (*models.EntropyReport, error) {
+	var population int64
+	values := make(map[string]map[string]int64) // Count of each value, per signal
+	vectors := make(map[string]int64)           // Count of each distinct feature vector
+	err := s.eachPopulationKeys(ctx, filter, func(keys []string) {
+		population++
+		vectors[similarity.FeatureSetHash(keys)]++
+		for _, key := range keys {
+			signal, _, _ := strings.Cut(key, ":")
+			if values[signal] == nil {
+				values[signal] = make(map[string]int64)
+			}
+			values[signal][key]++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.EntropyReport{
+		Days:       filter.Days,
+		Origin:     filter.Origin,
+		Country:    filter.Country,
+		Population: population,
+		Signals:    make([]models.SignalEntropy, 0, len(values)),
+	}
+
+	for signal, distinct := range values {
+		bits := entropy.Shannon(entropy.WithMissing(slices.Collect(maps.Values(distinct)), population))
+		report.Signals = append(report.Signals, models.SignalEntropy{
+			Signal:     signal,
+			Bits:       bits,
+			Normalized: entropy.Normalized(bits, population),
+			Distinct:   len(distinct),
+		})
+		report.TotalBits += bits
+	}
+	sort.Slice(report.Signals, func(i, j int) bool {
+		if report.Signals[i].Bits != report.Signals[j].Bits {
+			return report.Signals[i].Bits > report.Signals[j].Bits
+		}
+		return report.Signals[i].Signal < report.Signals[j].Signal
+	})
+
+	report.JointBits = entropy.Shannon(slices.Collect(maps.Values(vectors)))
+	report.NormalizedJoint = entropy.Normalized(report.JointBits, population)
+
+	return report, nil
+}
+
+// eachPopulationKeys calls fn with the feature keys of each visitor
+// selected by filter. Rows stored under analytics consent hold keys hashed
+// with the feature hash key, so every other row's keys are hashed the same
+// way first: one value must not count as two.
+func (s *IdentificationService) eachPopulationKeys(ctx context.Context, filter models.EntropyFilter, fn func([]string)) error {
+	key, err := s.featureHashKey(ctx)
+	if err != nil {
+		return err
+	}
+	return s.repo.EachPopulationFeatures(ctx, filter, func(features []string) {
+		fn(similarity.NormalizeFeatureKeys(key, features))
+	})
+}
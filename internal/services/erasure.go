@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/logger"
+)
+
+// EraseVisitor deletes everything stored about a visitor and records the
+// erasure. Redis is cleaned after the database commit; a cache failure is
+// logged rather than returned, since the erasure itself has happened and
+// hw: keys expire within the cache TTL regardless.
+func (s *IdentificationService) EraseVisitor(
+	ctx context.Context,
+	visitorID uuid.UUID,
+	reason string,
+	block bool,
+) (*models.Erasure, error) {
+	erased, err := s.repo.EraseVisitor(ctx, visitorID, optionalString(reason), block)
+	if err != nil {
+		return nil, err
+	}
+
+	erasure := erased.Erasure
+	id := visitorID.String()
+
+	for _, hash := range erased.HardwareHashes {
+		deleted, err := s.cache.DeleteVisitorID(ctx, hash, id)
+		if err != nil {
+			logger.Warn("Failed to clear cached visitor mapping", map[string]any{
+				"error":      err.Error(),
+				"erasure_id": erasure.ErasureID,
+			})
+			continue
+		}
+		if deleted {
+			erasure.CacheKeysDeleted++
+		}
+	}
+
+	if s.velocity != nil {
+		deleted, err := s.velocity.Forget(ctx, id, erased.Subnets, erased.HardwareHashes)
+		if err != nil {
+			logger.Warn("Failed to clear velocity counters", map[string]any{
+				"error":      err.Error(),
+				"erasure_id": erasure.ErasureID,
+			})
+		}
+		erasure.CacheKeysDeleted += deleted
+	}
+
+	counted, err := forgetAnonymity(ctx, s.cache, id)
+	if err != nil {
+		logger.Warn("Failed to clear uniqueness count", map[string]any{
+			"error":      err.Error(),
+			"erasure_id": erasure.ErasureID,
+		})
+	}
+	if counted {
+		erasure.CacheKeysDeleted++
+	}
+
+	_ = s.cache.IncrementMetric(ctx, "visitor_erasures")
+	return &erasure, nil
+}
+
+// respondBlocked answers a device blocked by an erasure. Nothing is stored
+// and no visitor ID is issued.
+func (s *IdentificationService) respondBlocked(
+	ctx context.Context,
+	req models.IdentifyRequest,
+	bot models.BotResult,
+) (*models.IdentifyResponse, error) {
+	_ = s.cache.IncrementMetric(ctx, "blocked_identifications")
+
+	ident := &models.Identification{
+		RequestID:  uuid.New(),
+		VisitorID:  uuid.Nil,
+		CreatedAt:  time.Now(),
+		BotVerdict: bot.Verdict,
+		BotScore:   bot.Score,
+		BotReasons: bot.Reasons,
+		Origin:     optionalString(req.Origin),
+		Consent:    req.Consent,
+	}
+
+	resp, err := s.respond(ident, false)
+	if err != nil {
+		return nil, err
+	}
+	resp.Blocked = true
+	return resp, nil
+}
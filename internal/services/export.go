@@ -0,0 +1,141 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/cache"
+)
+
+// exportPageSize is how many identifications are read per query.
+const exportPageSize = 500
+
+// ExportVisitor collects everything stored about a visitor: the visitor
+// record with its trust score, every identification with signals and
+// derived scores, the linked IDs supplied with them, and the Redis entries
+// keyed by the visitor or pointing at it.
+func (s *IdentificationService) ExportVisitor(ctx context.Context, visitorID uuid.UUID) (*models.VisitorExport, error) {
+	visitor, err := s.repo.GetVisitor(ctx, visitorID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.VisitorExport{
+		ExportedAt:      time.Now().UTC(),
+		Visitor:         *visitor,
+		Identifications: []models.Identification{},
+		LinkedIDs:       []string{},
+		CacheEntries:    []models.CacheEntry{},
+	}
+
+	var before *time.Time
+	var beforeID uuid.UUID
+	for {
+		page, err := s.repo.GetVisitorIdentifications(ctx, visitorID, before, beforeID, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		export.Identifications = append(export.Identifications, page...)
+		if len(page) < exportPageSize {
+			break
+		}
+		last := page[len(page)-1]
+		before, beforeID = &last.CreatedAt, last.RequestID
+	}
+
+	var hashes []string
+	for _, ident := range export.Identifications {
+		if ident.LinkedID != nil && !slices.Contains(export.LinkedIDs, *ident.LinkedID) {
+			export.LinkedIDs = append(export.LinkedIDs, *ident.LinkedID)
+		}
+		if ident.HardwareHash != "" && !slices.Contains(hashes, ident.HardwareHash) {
+			hashes = append(hashes, ident.HardwareHash)
+		}
+	}
+
+	for _, hash := range hashes {
+		entry, err := s.cache.VisitorIDEntry(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil && slices.Contains(entry.Values, visitorID.String()) {
+			export.CacheEntries = append(export.CacheEntries, exportCacheEntry(*entry))
+		}
+	}
+
+	anonymity, err := anonymityEntry(ctx, s.cache, visitorID.String())
+	if err != nil {
+		return nil, err
+	}
+	if anonymity != nil {
+		export.CacheEntries = append(export.CacheEntries, exportCacheEntry(*anonymity))
+	}
+
+	if s.velocity != nil {
+		entries, err := s.velocity.Entries(ctx, visitorID.String())
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			export.CacheEntries = append(export.CacheEntries, exportCacheEntry(entry))
+		}
+	}
+
+	return export, nil
+}
+
+// exportCacheEntry converts a raw cache entry to its export form.
+func exportCacheEntry(entry cache.Entry) models.CacheEntry {
+	var expiresIn int64
+	if entry.ExpiresIn > 0 {
+		expiresIn = int64(entry.ExpiresIn.Seconds())
+	}
+	return models.CacheEntry{
+		Key:       entry.Key,
+		Values:    entry.Values,
+		ExpiresIn: expiresIn,
+	}
+}
+
+// WriteExportZip writes an export as a zip archive with one JSON file per
+// section.
+func WriteExportZip(w io.Writer, export *models.VisitorExport) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"visitor.json", map[string]any{"exported_at": export.ExportedAt, "visitor": export.Visitor}},
+		{"identifications.json", export.Identifications},
+		{"linked_ids.json", export.LinkedIDs},
+		{"cache_entries.json", export.CacheEntries},
+	}
+
+	for _, file := range files {
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add %s to export: %w", file.name, err)
+		}
+
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			return fmt.Errorf("failed to write %s to export: %w", file.name, err)
+		}
+	}
+
+	return zw.Close()
+}
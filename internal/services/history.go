@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/useragent"
+)
+
+// ErrInvalidCursor is returned for a malformed pagination cursor.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	maxHistorySubnets    = 100
+	maxHistoryUserAgents = 500
+)
+
+// GetVisitorHistory returns a visitor with one page of identifications.
+// The cursor is the NextCursor of a previous page, or empty for the first.
+func (s *IdentificationService) GetVisitorHistory(
+	ctx context.Context,
+	visitorID uuid.UUID,
+	cursor string,
+	limit int,
+) (*models.VisitorHistory, error) {
+	before, beforeID, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	visitor, err := s.repo.GetVisitor(ctx, visitorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to learn whether another page exists.
+	identifications, err := s.repo.GetVisitorIdentifications(ctx, visitorID, before, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &models.VisitorHistory{
+		Visitor:         *visitor,
+		Identifications: identifications,
+	}
+	if len(identifications) > limit {
+		history.Identifications = identifications[:limit]
+		last := history.Identifications[limit-1]
+		history.NextCursor = encodeCursor(last.CreatedAt, last.RequestID)
+	}
+
+	if history.Subnets, err = s.repo.GetVisitorSubnets(ctx, visitorID, maxHistorySubnets); err != nil {
+		return nil, err
+	}
+
+	userAgents, err := s.repo.GetVisitorUserAgents(ctx, visitorID, maxHistoryUserAgents)
+	if err != nil {
+		return nil, err
+	}
+	history.Browsers, history.OperatingSystems = summarizeUserAgents(userAgents)
+
+	return history, nil
+}
+
+// summarizeUserAgents reduces raw user agents to sorted, distinct browser
+// ("Chrome 120") and OS names.
+func summarizeUserAgents(userAgents []string) (browsers, systems []string) {
+	browsers, systems = []string{}, []string{}
+	for _, ua := range userAgents {
+		info := useragent.Parse(ua)
+
+		browser := strings.TrimSpace(info.Browser + " " + info.BrowserVersion)
+		if !slices.Contains(browsers, browser) {
+			browsers = append(browsers, browser)
+		}
+		if !slices.Contains(systems, info.OS) {
+			systems = append(systems, info.OS)
+		}
+	}
+	slices.Sort(browsers)
+	slices.Sort(systems)
+	return browsers, systems
+}
+
+func encodeCursor(createdAt time.Time, requestID uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + requestID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (*time.Time, uuid.UUID, error) {
+	if cursor == "" {
+		return nil, uuid.Nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, uuid.Nil, ErrInvalidCursor
+	}
+
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, uuid.Nil, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	requestID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return &createdAt, requestID, nil
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
-	"strings"
+	"net/netip"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,8 +13,16 @@ import (
 	"github.com/iamgideonidoko/signet/internal/config"
 	"github.com/iamgideonidoko/signet/internal/models"
 	"github.com/iamgideonidoko/signet/internal/repository"
+	"github.com/iamgideonidoko/signet/pkg/botdetect"
+	"github.com/iamgideonidoko/signet/pkg/budget"
 	"github.com/iamgideonidoko/signet/pkg/cache"
+	"github.com/iamgideonidoko/signet/pkg/geoip"
+	"github.com/iamgideonidoko/signet/pkg/goodbot"
+	"github.com/iamgideonidoko/signet/pkg/ipintel"
+	"github.com/iamgideonidoko/signet/pkg/logger"
+	"github.com/iamgideonidoko/signet/pkg/sealed"
 	"github.com/iamgideonidoko/signet/pkg/similarity"
+	"github.com/iamgideonidoko/signet/pkg/tamper"
 )
 
 type IdentificationService struct {
@@ -20,12 +30,122 @@ type IdentificationService struct {
 	cache      *cache.Cache
 	calculator *similarity.Calculator
 	config     *config.FingerprintConfig
+	sealer     *sealed.Sealer
+	botEngine  *botdetect.Engine
+	goodBots   *goodbot.Verifier
+	ipIntel    *ipintel.Database
+	geoIP      *geoip.Reader
+	velocity   *VelocityChecker
+	uniqueness *UniquenessTracker
+	pseudonyms *IPPseudonymizer
+	budgets    *budget.Policy
+	tamper     *tamper.Checker
+	optOut     models.OptOutPolicy
+	consent    models.ConsentState
+
+	featureKeyMu sync.Mutex
+	featureKey   []byte // Keys feature hashes; loaded from Redis when not configured
+}
+
+// Option configures optional IdentificationService features.
+type Option func(*IdentificationService)
+
+// WithSealer attaches a signed result token to every identify response.
+func WithSealer(sealer *sealed.Sealer) Option {
+	return func(s *IdentificationService) {
+		s.sealer = sealer
+	}
+}
+
+// WithBotEngine replaces the default bot detection rules.
+func WithBotEngine(engine *botdetect.Engine) Option {
+	return func(s *IdentificationService) {
+		s.botEngine = engine
+	}
+}
+
+// WithGoodBotVerifier classifies crawlers by their published IP ranges.
+// Verified crawlers are answered without creating or storing a visitor.
+func WithGoodBotVerifier(verifier *goodbot.Verifier) Option {
+	return func(s *IdentificationService) {
+		s.goodBots = verifier
+	}
+}
+
+// WithIPIntel flags Tor, VPN, proxy and datacenter client IPs. The flags
+// are stored and fed into bot scoring.
+func WithIPIntel(db *ipintel.Database) Option {
+	return func(s *IdentificationService) {
+		s.ipIntel = db
+	}
+}
+
+// WithGeoIP stores the coarse location and ASN of each client IP.
+func WithGeoIP(reader *geoip.Reader) Option {
+	return func(s *IdentificationService) {
+		s.geoIP = reader
+	}
+}
+
+// WithVelocityChecker flags identifications that exceed rolling-window limits.
+func WithVelocityChecker(checker *VelocityChecker) Option {
+	return func(s *IdentificationService) {
+		s.velocity = checker
+	}
+}
+
+// WithUniquenessTracker annotates identifications with the size of their
+// anonymity set and how unique they are within the population.
+func WithUniquenessTracker(tracker *UniquenessTracker) Option {
+	return func(s *IdentificationService) {
+		s.uniqueness = tracker
+	}
+}
+
+// WithEntropyBudgets restricts the signals used to identify visitors per
+// origin or API key. Disallowed signals are neither matched nor stored.
+func WithEntropyBudgets(policy *budget.Policy) Option {
+	return func(s *IdentificationService) {
+		s.budgets = policy
+	}
+}
+
+// WithIPPseudonymizer stores keyed hashes in place of IPs and subnets.
+func WithIPPseudonymizer(pseudonymizer *IPPseudonymizer) Option {
+	return func(s *IdentificationService) {
+		s.pseudonyms = pseudonymizer
+	}
+}
+
+// WithOptOutPolicy sets how requests with Do Not Track or Global Privacy
+// Control are handled. The default, OptOutIgnore, identifies them as usual.
+func WithOptOutPolicy(policy models.OptOutPolicy) Option {
+	return func(s *IdentificationService) {
+		s.optOut = policy
+	}
+}
+
+// WithDefaultConsent sets the consent state of requests that do not state
+// one. The default, ConsentFull, stores raw signals.
+func WithDefaultConsent(consent models.ConsentState) Option {
+	return func(s *IdentificationService) {
+		s.consent = consent
+	}
+}
+
+// WithFeatureHashKey sets the secret feature keys are hashed with under
+// analytics consent. Changing it stops stored hashed rows from matching.
+func WithFeatureHashKey(key []byte) Option {
+	return func(s *IdentificationService) {
+		s.featureKey = key
+	}
 }
 
 func NewIdentificationService(
 	repo *repository.Repository,
 	cache *cache.Cache,
 	cfg *config.FingerprintConfig,
+	opts ...Option,
 ) *IdentificationService {
 	weights := similarity.Weights{
 		Hardware:    cfg.HardwareWeight,
@@ -33,61 +153,215 @@ func NewIdentificationService(
 		Software:    cfg.SoftwareWeight,
 	}
 
-	return &IdentificationService{
+	s := &IdentificationService{
 		repo:       repo,
 		cache:      cache,
 		calculator: similarity.NewCalculator(weights),
 		config:     cfg,
+		botEngine:  botdetect.NewEngine(botdetect.DefaultRules(), botdetect.DefaultConfig),
+		tamper:     tamper.NewChecker(tamper.DefaultChecks()),
+		optOut:     models.OptOutIgnore,
+		consent:    models.ConsentFull,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // Identify performs the "Healer" logic: probabilistic matching with self-healing.
 func (s *IdentificationService) Identify(ctx context.Context, req models.IdentifyRequest) (*models.IdentifyResponse, error) {
-	hardwareHash := similarity.ComputeHardwareHash(req.Signals)
-
-	cachedVisitorID, err := s.cache.GetVisitorID(ctx, hardwareHash)
-	if err == nil && cachedVisitorID != "" {
-		visitorUUID, _ := uuid.Parse(cachedVisitorID)
-
-		ident := &models.Identification{
-			RequestID:       uuid.New(),
-			VisitorID:       visitorUUID,
-			IPAddress:       req.IPAddress,
-			Signals:         req.Signals,
-			ConfidenceScore: 1.0,
-			CreatedAt:       time.Now(),
-			HardwareHash:    hardwareHash,
-			IsBot:           s.detectBot(req.Signals),
+	if req.Consent == "" {
+		req.Consent = s.consent
+	}
+
+	// Bot and tamper checks see every signal; matching and storage only the
+	// ones within the entropy budget.
+	botInput := botdetect.NewInput(req.Signals, req.Headers)
+	tampering := s.tamper.Detect(req.Signals)
+	var usage budget.Usage
+	req.Signals, usage = s.entropyBudget(req).Apply(req.Signals)
+
+	hardwareHash := usage.HardwareHash
+	keys := similarity.WithoutCategories(similarity.FeatureKeys(req.Signals), usage.Cleared)
+	if s.goodBots != nil {
+		crawler := s.goodBots.Classify(requestUserAgent(req), parseClientIP(req.ClientIP))
+		if crawler.Verified {
+			return s.respondGoodBot(ctx, req, crawler.Crawler)
 		}
+		botInput.Crawler = crawler.Crawler
+	}
+	if s.ipIntel != nil {
+		botInput.IPFlags = s.ipIntel.Lookup(parseClientIP(req.ClientIP))
+	}
+	bot := s.botEngine.Evaluate(botInput)
 
-		if err := s.repo.CreateIdentification(ctx, ident); err != nil {
-			return nil, fmt.Errorf("failed to save identification: %w", err)
+	privacy := s.privacyDecision(req)
+	if privacy != nil {
+		_ = s.cache.IncrementMetric(ctx, "opt_out_"+string(privacy.Policy))
+		if privacy.Policy != models.OptOutIgnore {
+			return s.respondOptedOut(ctx, req, bot, privacy)
 		}
+	}
+	if req.Consent == models.ConsentNone {
+		return s.respondSession(ctx, req, bot)
+	}
 
-		_ = s.cache.IncrementMetric(ctx, "cache_hits")
+	var pseudonyms Pseudonyms
+	if s.pseudonyms != nil {
+		subnet := s.config.SubnetPrefixes.Subnet(req.IPAddress)
+		p, err := s.pseudonyms.Pseudonymize(ctx, req.IPAddress, subnet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pseudonymize IP: %w", err)
+		}
+		pseudonyms = p
+	}
 
-		return &models.IdentifyResponse{
-			VisitorID:  visitorUUID,
-			Confidence: 1.0,
-			IsNew:      false,
-			RequestID:  ident.RequestID,
-		}, nil
+	match, err := s.resolveVisitor(ctx, req, keys, hardwareHash, pseudonyms)
+	if err != nil {
+		return nil, err
+	}
+	if match.blocked {
+		return s.respondBlocked(ctx, req, bot)
+	}
+
+	var featureKey []byte
+	if req.Consent == models.ConsentAnalytics {
+		if featureKey, err = s.featureHashKey(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	ident := s.newIdentification(req, keys, match.visitorID, match.confidence, hardwareHash, bot, featureKey)
+	ident.Tampering = tampering
+	ident.IPFlags = botInput.IPFlags
+	if s.pseudonyms != nil {
+		ident.IPAddress, ident.IPHash = "", pseudonyms.IP
+		ident.IPSubnet, ident.IPSubnetHash = "", pseudonyms.Subnet
+	}
+	if s.geoIP != nil {
+		ident.Geo = s.geoIP.Lookup(parseClientIP(req.ClientIP))
+	}
+
+	if s.velocity != nil {
+		subnet := ident.IPSubnet
+		if ident.IPSubnetHash != "" {
+			subnet = ident.IPSubnetHash
+		}
+		ident.RiskFlags = s.velocity.Check(ctx, ident, subnet, match.isNew)
+	}
+
+	if err := s.repo.CreateIdentification(ctx, ident); err != nil {
+		return nil, fmt.Errorf("failed to save identification: %w", err)
+	}
+
+	// Only stored identifications are counted, and bots stay out of the
+	// population; a counter failure only drops the annotation.
+	if s.uniqueness != nil && !ident.IsBot {
+		if err := s.trackUniqueness(ctx, ident, keys); err != nil {
+			logger.Warn("Failed to track uniqueness", map[string]any{
+				"error":      err.Error(),
+				"visitor_id": ident.VisitorID,
+			})
+		}
+	}
+
+	resp, err := s.respond(ident, match.isNew)
+	if err != nil {
+		return nil, err
 	}
+	resp.Privacy = privacy
+	resp.SignalsUsed, resp.EntropyBits = usage.Groups, usage.Bits
 
-	incomingVector := s.calculator.ExtractFeatures(req.Signals)
-	ipSubnet := s.extractIPSubnet(req.IPAddress)
+	// A failed trust update must not fail an identification that is already stored.
+	if score, err := s.UpdateTrustScore(ctx, ident.VisitorID); err == nil {
+		resp.TrustScore = &score
+	} else {
+		logger.Warn("Failed to update trust score", map[string]any{
+			"error":      err.Error(),
+			"visitor_id": ident.VisitorID,
+		})
+	}
+
+	return resp, nil
+}
+
+// entropyBudget returns the budget for a request, or nil when every signal
+// may be used.
+func (s *IdentificationService) entropyBudget(req models.IdentifyRequest) *budget.Budget {
+	if s.budgets == nil {
+		return nil
+	}
+	return s.budgets.For(req.Origin, req.APIKey)
+}
+
+// visitorMatch is the outcome of resolving a request to a visitor.
+type visitorMatch struct {
+	visitorID  uuid.UUID
+	confidence float64
+	isNew      bool
+	blocked    bool // The device was blocked by an erasure request
+}
 
-	candidates, err := s.repo.FindSimilarVisitors(ctx, ipSubnet, 50)
+// resolveVisitor finds the visitor for a request: first via the hardware hash
+// cache, then by similarity against recent visitors in the same subnet,
+// creating a new visitor when nothing matches unless the device is blocked.
+// Without a hardware hash, as under an entropy budget that clears hardware
+// signals, only similarity matching applies.
+func (s *IdentificationService) resolveVisitor(
+	ctx context.Context,
+	req models.IdentifyRequest,
+	incomingKeys []string,
+	hardwareHash string,
+	pseudonyms Pseudonyms,
+) (visitorMatch, error) {
+	if hardwareHash != "" {
+		cachedVisitorID, err := s.cache.GetVisitorID(ctx, hardwareHash)
+		if err == nil && cachedVisitorID != "" {
+			visitorUUID, _ := uuid.Parse(cachedVisitorID)
+
+			_ = s.cache.IncrementMetric(ctx, "cache_hits")
+
+			return visitorMatch{visitorID: visitorUUID, confidence: 1.0}, nil
+		}
+	}
+
+	incomingVector := s.calculator.Vector(incomingKeys)
+	ipSubnet := s.config.SubnetPrefixes.Subnet(req.IPAddress)
+
+	// Rows stored before pseudonymization was enabled still match by subnet.
+	var subnetHashes []string
+	if s.pseudonyms != nil {
+		var err error
+		if subnetHashes, err = s.pseudonyms.SubnetHashes(ctx, ipSubnet); err != nil {
+			return visitorMatch{}, fmt.Errorf("failed to hash subnet: %w", err)
+		}
+	}
+
+	candidates, err := s.repo.FindSimilarVisitors(ctx, ipSubnet, subnetHashes, 50)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find similar visitors: %w", err)
+		return visitorMatch{}, fmt.Errorf("failed to find similar visitors: %w", err)
 	}
 
 	var bestMatch *models.Identification
 	var bestScore = 0.0
+	var hashedVector *similarity.FeatureVector
 
 	for _, candidate := range candidates {
-		candidateVector := s.calculator.ExtractFeatures(candidate.Signals)
-		score := s.calculator.JaccardSimilarity(incomingVector, candidateVector)
+		// Rows stored under analytics consent only hold hashed feature keys.
+		vector := incomingVector
+		if candidate.Consent == models.ConsentAnalytics {
+			if hashedVector == nil {
+				key, err := s.featureHashKey(ctx)
+				if err != nil {
+					return visitorMatch{}, err
+				}
+				v := s.calculator.Vector(similarity.HashFeatureKeys(key, incomingKeys))
+				hashedVector = &v
+			}
+			vector = *hashedVector
+		}
+		score := s.calculator.JaccardSimilarity(vector, s.candidateVector(candidate))
 
 		if score > bestScore {
 			bestScore = score
@@ -95,94 +369,224 @@ func (s *IdentificationService) Identify(ctx context.Context, req models.Identif
 		}
 	}
 
-	var visitorID uuid.UUID
-	var confidence float64
-	var isNew bool
-
 	if bestScore >= s.config.SimilarityThreshold && bestMatch != nil {
 		// Match found! Use existing visitorID (Self-Healing)
-		visitorID = bestMatch.VisitorID
-		confidence = bestScore
-		isNew = false
-
-		_ = s.cache.SetVisitorID(ctx, hardwareHash, visitorID.String())
+		if hardwareHash != "" {
+			_ = s.cache.SetVisitorID(ctx, hardwareHash, bestMatch.VisitorID.String())
+		}
 		_ = s.cache.IncrementMetric(ctx, "healed_identifications")
-	} else {
-		visitor, err := s.repo.CreateVisitor(ctx, req.IPAddress)
+
+		return visitorMatch{visitorID: bestMatch.VisitorID, confidence: bestScore}, nil
+	}
+
+	if hardwareHash != "" {
+		blocked, err := s.repo.IsDeviceBlocked(ctx, hardwareHash)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create visitor: %w", err)
+			return visitorMatch{}, err
+		}
+		if blocked {
+			return visitorMatch{blocked: true}, nil
 		}
+	}
 
-		visitorID = visitor.VisitorID
-		confidence = 1.0
-		isNew = true
+	ipAddress := req.IPAddress
+	if pseudonyms.IP != "" {
+		ipAddress = ""
+	}
+	visitor, err := s.repo.CreateVisitor(ctx, ipAddress, pseudonyms.IP)
+	if err != nil {
+		return visitorMatch{}, fmt.Errorf("failed to create visitor: %w", err)
+	}
 
-		_ = s.cache.SetVisitorID(ctx, hardwareHash, visitorID.String())
-		_ = s.cache.IncrementMetric(ctx, "new_visitors")
+	if hardwareHash != "" {
+		_ = s.cache.SetVisitorID(ctx, hardwareHash, visitor.VisitorID.String())
 	}
+	_ = s.cache.IncrementMetric(ctx, "new_visitors")
+
+	return visitorMatch{visitorID: visitor.VisitorID, confidence: 1.0, isNew: true}, nil
+}
+
+// respondGoodBot answers a verified crawler. Nothing is persisted, so good
+// bots never create visitors or show up in analytics.
+func (s *IdentificationService) respondGoodBot(
+	ctx context.Context,
+	req models.IdentifyRequest,
+	crawler string,
+) (*models.IdentifyResponse, error) {
+	_ = s.cache.IncrementMetric(ctx, "good_bot_requests")
 
+	ident := &models.Identification{
+		RequestID:  uuid.New(),
+		VisitorID:  uuid.Nil,
+		CreatedAt:  time.Now(),
+		BotVerdict: models.VerdictGoodBot,
+		BotReasons: []string{botdetect.ReasonVerifiedCrawler},
+		Origin:     optionalString(req.Origin),
+		Consent:    req.Consent,
+	}
+
+	resp, err := s.respond(ident, false)
+	if err != nil {
+		return nil, err
+	}
+	resp.Bot.Crawler = crawler
+	return resp, nil
+}
+
+// requestUserAgent prefers the HTTP User-Agent, which crawlers always send,
+// over the one reported by the agent script.
+func requestUserAgent(req models.IdentifyRequest) string {
+	if req.Headers.UserAgent != "" {
+		return req.Headers.UserAgent
+	}
+	return req.Signals.UserAgent
+}
+
+// parseClientIP returns the zero Addr for a missing or invalid IP, which
+// matches no range.
+func parseClientIP(ip string) netip.Addr {
+	addr, _ := netip.ParseAddr(ip)
+	return addr
+}
+
+// respond builds the identify response, sealing it when a sealer is configured.
+func (s *IdentificationService) respond(ident *models.Identification, isNew bool) (*models.IdentifyResponse, error) {
+	resp := &models.IdentifyResponse{
+		VisitorID:  ident.VisitorID,
+		Confidence: ident.ConfidenceScore,
+		IsNew:      isNew,
+		RequestID:  ident.RequestID,
+		Bot: models.BotResult{
+			Verdict: ident.BotVerdict,
+			Score:   ident.BotScore,
+			Reasons: ident.BotReasons,
+		},
+		RiskFlags:  ident.RiskFlags,
+		Tampering:  ident.Tampering,
+		IPFlags:    ident.IPFlags,
+		Geo:        ident.Geo,
+		Consent:    ident.Consent,
+		Uniqueness: ident.Uniqueness,
+	}
+
+	if s.sealer != nil {
+		token, err := s.sealer.Seal(sealed.Payload{
+			VisitorID:  ident.VisitorID,
+			RequestID:  ident.RequestID,
+			Confidence: ident.ConfidenceScore,
+			BotVerdict: string(ident.BotVerdict),
+			Timestamp:  ident.CreatedAt.UnixMilli(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal result: %w", err)
+		}
+		resp.SealedResult = token
+	}
+
+	return resp, nil
+}
+
+// newIdentification builds the identification record for a request with its
+// feature keys, carrying over any caller-supplied metadata. Under analytics
+// consent only feature keys hashed with featureKey are kept; raw signals and
+// the user agent are dropped.
+func (s *IdentificationService) newIdentification(
+	req models.IdentifyRequest,
+	keys []string,
+	visitorID uuid.UUID,
+	confidence float64,
+	hardwareHash string,
+	bot models.BotResult,
+	featureKey []byte,
+) *models.Identification {
 	ident := &models.Identification{
 		RequestID:       uuid.New(),
 		VisitorID:       visitorID,
 		IPAddress:       req.IPAddress,
+		IPSubnet:        s.config.SubnetPrefixes.Subnet(req.IPAddress),
+		UserAgent:       optionalString(req.Headers.UserAgent),
 		Signals:         req.Signals,
+		Features:        keys,
 		ConfidenceScore: confidence,
 		CreatedAt:       time.Now(),
 		HardwareHash:    hardwareHash,
-		IsBot:           s.detectBot(req.Signals),
+		IsBot:           bot.Verdict.IsBot(),
+		BotVerdict:      bot.Verdict,
+		BotScore:        bot.Score,
+		BotReasons:      bot.Reasons,
+		Tag:             req.Tag,
+		LinkedID:        optionalString(req.LinkedID),
+		URL:             optionalString(req.URL),
+		Referrer:        optionalString(req.Referrer),
+		Origin:          optionalString(req.Origin),
+		Consent:         req.Consent,
 	}
 
-	if err := s.repo.CreateIdentification(ctx, ident); err != nil {
-		return nil, fmt.Errorf("failed to save identification: %w", err)
+	if req.Consent == models.ConsentAnalytics {
+		ident.Signals = models.Signals{}
+		ident.UserAgent = nil
+		ident.Features = similarity.HashFeatureKeys(featureKey, ident.Features)
 	}
-
-	return &models.IdentifyResponse{
-		VisitorID:  visitorID,
-		Confidence: confidence,
-		IsNew:      isNew,
-		RequestID:  ident.RequestID,
-	}, nil
+	return ident
 }
 
-func (s *IdentificationService) extractIPSubnet(ip string) string {
-	parts := strings.Split(ip, ".")
-	if len(parts) != 4 {
-		return ip
-	}
-	return fmt.Sprintf("%s.%s.%s.0/24", parts[0], parts[1], parts[2])
-}
+// featureHashKey returns the secret feature keys are hashed with: the
+// configured one, or else a random key shared through Redis and created on
+// first use. It is loaded once per process so matching stays consistent.
+func (s *IdentificationService) featureHashKey(ctx context.Context) ([]byte, error) {
+	s.featureKeyMu.Lock()
+	defer s.featureKeyMu.Unlock()
 
-func (s *IdentificationService) detectBot(signals models.Signals) bool {
-	if signals.WebDriver || signals.PhantomPresent ||
-		signals.SeleniumPresent || signals.AutomationPresent {
-		return true
+	if s.featureKey != nil {
+		return s.featureKey, nil
 	}
 
-	// Headless Chrome
-	if signals.HeadlessChrome {
-		return true
+	candidate := make([]byte, 32)
+	if _, err := rand.Read(candidate); err != nil {
+		return nil, fmt.Errorf("failed to generate feature hash key: %w", err)
 	}
-
-	if signals.Canvas2DHash == "" || signals.Canvas2DHash == "error" ||
-		signals.AudioHash == "" || signals.AudioHash == "error" {
-		return true
+	key, err := s.cache.GetOrCreateSalt(ctx, "features", candidate, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feature hash key: %w", err)
 	}
+	s.featureKey = key
+	return key, nil
+}
 
-	if signals.HardwareConcurrency == 0 || signals.DeviceMemory == 0 {
-		return true
+// candidateVector weights a stored identification's feature keys, which
+// outlive its raw signals. Rows stored before feature keys existed fall back
+// to their signals.
+func (s *IdentificationService) candidateVector(ident models.Identification) similarity.FeatureVector {
+	if len(ident.Features) > 0 {
+		return s.calculator.Vector(ident.Features)
 	}
+	return s.calculator.ExtractFeatures(ident.Signals)
+}
 
-	if signals.WebGLVendor == "Brian Paul" || signals.WebGLRenderer == "Google SwiftShader" {
-		return true
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
 	}
+	return &s
+}
 
-	return false
+// GetIdentification returns a stored identification event by request ID.
+func (s *IdentificationService) GetIdentification(ctx context.Context, requestID uuid.UUID) (*models.Identification, error) {
+	return s.repo.GetIdentification(ctx, requestID)
 }
 
 func (s *IdentificationService) GetAnalytics(ctx context.Context, days int) ([]models.VisitorAnalytics, error) {
 	return s.repo.GetAnalytics(ctx, days)
 }
 
-func (s *IdentificationService) GetRecentIdentifications(ctx context.Context, limit, offset int) ([]models.Identification, error) {
-	return s.repo.GetRecentIdentifications(ctx, limit, offset)
+func (s *IdentificationService) GetGeoBreakdown(ctx context.Context, days int) (*models.GeoBreakdown, error) {
+	return s.repo.GetGeoBreakdown(ctx, days)
+}
+
+func (s *IdentificationService) GetRecentIdentifications(
+	ctx context.Context,
+	filter models.IdentificationFilter,
+	limit, offset int,
+) ([]models.Identification, error) {
+	return s.repo.GetRecentIdentifications(ctx, filter, limit, offset)
 }
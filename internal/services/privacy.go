@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+)
+
+// privacyDecision returns how the configured opt-out policy applies to a
+// request, or nil when it carries neither Do Not Track nor Global Privacy
+// Control.
+func (s *IdentificationService) privacyDecision(req models.IdentifyRequest) *models.PrivacyDecision {
+	var signals []string
+	if doNotTrack(req.Signals.DoNotTrack) || doNotTrack(req.Headers.DNT) {
+		signals = append(signals, models.OptOutSignalDNT)
+	}
+	if strings.TrimSpace(req.Headers.SecGPC) == "1" {
+		signals = append(signals, models.OptOutSignalGPC)
+	}
+	if len(signals) == 0 {
+		return nil
+	}
+	return &models.PrivacyDecision{Signals: signals, Policy: s.optOut}
+}
+
+// doNotTrack reports whether a navigator.doNotTrack value or DNT header
+// asks not to be tracked. Older Firefox releases report "yes".
+func doNotTrack(value string) bool {
+	value = strings.TrimSpace(value)
+	return value == "1" || value == "yes"
+}
+
+// respondOptedOut answers a request that opted out of tracking under the
+// refuse, ephemeral or aggregate policy. No visitor is matched or created
+// and nothing about the request is stored; the aggregate policy only
+// increments counters.
+func (s *IdentificationService) respondOptedOut(
+	ctx context.Context,
+	req models.IdentifyRequest,
+	bot models.BotResult,
+	privacy *models.PrivacyDecision,
+) (*models.IdentifyResponse, error) {
+	ident := &models.Identification{
+		RequestID:  uuid.New(),
+		VisitorID:  uuid.Nil,
+		CreatedAt:  time.Now(),
+		BotVerdict: bot.Verdict,
+		BotScore:   bot.Score,
+		BotReasons: bot.Reasons,
+		Origin:     optionalString(req.Origin),
+		Consent:    req.Consent,
+	}
+
+	isNew := false
+	switch privacy.Policy {
+	case models.OptOutEphemeral:
+		ident.VisitorID = uuid.New()
+		isNew = true
+	case models.OptOutAggregate:
+		_ = s.cache.IncrementMetric(ctx, "aggregate_requests")
+		if bot.Verdict.IsBot() {
+			_ = s.cache.IncrementMetric(ctx, "aggregate_bot_requests")
+		}
+	}
+
+	resp, err := s.respond(ident, isNew)
+	if err != nil {
+		return nil, err
+	}
+	resp.Privacy = privacy
+	return resp, nil
+}
+
+// respondSession answers a request without tracking consent. The visitor ID
+// is scoped to the session: the one the client sends back is reused, or a
+// new one is issued. It is never stored or matched against history.
+func (s *IdentificationService) respondSession(
+	ctx context.Context,
+	req models.IdentifyRequest,
+	bot models.BotResult,
+) (*models.IdentifyResponse, error) {
+	_ = s.cache.IncrementMetric(ctx, "session_identifications")
+
+	sessionID, err := uuid.Parse(req.SessionID)
+	isNew := err != nil
+	if isNew {
+		sessionID = uuid.New()
+	}
+
+	ident := &models.Identification{
+		RequestID:  uuid.New(),
+		VisitorID:  sessionID,
+		CreatedAt:  time.Now(),
+		BotVerdict: bot.Verdict,
+		BotScore:   bot.Score,
+		BotReasons: bot.Reasons,
+		Origin:     optionalString(req.Origin),
+		Consent:    req.Consent,
+	}
+
+	return s.respond(ident, isNew)
+}
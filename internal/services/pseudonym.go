@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/iamgideonidoko/signet/internal/config"
+	"github.com/iamgideonidoko/signet/pkg/cache"
+)
+
+// IPPseudonymizer replaces stored IPs and subnets with keyed HMACs. The key
+// is a random salt shared through Redis that changes every rotation period.
+// Salts expire once they are older than the matching window, after which
+// hashes made with them can no longer be linked to any IP.
+type IPPseudonymizer struct {
+	cache  *cache.Cache
+	config *config.PseudonymizationConfig
+}
+
+// NewIPPseudonymizer returns a pseudonymizer keyed by salts in cache.
+func NewIPPseudonymizer(cache *cache.Cache, cfg *config.PseudonymizationConfig) *IPPseudonymizer {
+	return &IPPseudonymizer{cache: cache, config: cfg}
+}
+
+// Pseudonyms are the stored stand-ins for an IP and its subnet.
+type Pseudonyms struct {
+	IP     string
+	Subnet string
+}
+
+// Pseudonymize hashes ip and subnet with the current salt.
+func (p *IPPseudonymizer) Pseudonymize(ctx context.Context, ip, subnet string) (Pseudonyms, error) {
+	epoch := p.epoch(time.Now())
+
+	candidate := make([]byte, 32)
+	if _, err := rand.Read(candidate); err != nil {
+		return Pseudonyms{}, fmt.Errorf("failed to generate IP salt: %w", err)
+	}
+	salt, err := p.cache.GetOrCreateSalt(ctx, saltName(epoch), candidate, p.saltTTL())
+	if err != nil {
+		return Pseudonyms{}, err
+	}
+
+	return Pseudonyms{IP: hashIP(salt, ip), Subnet: hashIP(salt, subnet)}, nil
+}
+
+// SubnetHashes returns subnet hashed under every salt still kept for
+// matching, newest first.
+func (p *IPPseudonymizer) SubnetHashes(ctx context.Context, subnet string) ([]string, error) {
+	epoch := p.epoch(time.Now())
+	names := make([]string, 0, p.retainedEpochs()+1)
+	for e := epoch; e >= epoch-p.retainedEpochs(); e-- {
+		names = append(names, saltName(e))
+	}
+
+	salts, err := p.cache.GetSalts(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []string
+	for _, salt := range salts {
+		if salt != nil {
+			hashes = append(hashes, hashIP(salt, subnet))
+		}
+	}
+	return hashes, nil
+}
+
+func (p *IPPseudonymizer) epoch(t time.Time) int64 {
+	return t.Unix() / int64(p.config.SaltRotation.Seconds())
+}
+
+// retainedEpochs is how many earlier salts are kept for matching.
+func (p *IPPseudonymizer) retainedEpochs() int64 {
+	return int64((p.config.SaltRetention + p.config.SaltRotation - 1) / p.config.SaltRotation)
+}
+
+// saltTTL keeps a salt through its own period and the matching window.
+func (p *IPPseudonymizer) saltTTL() time.Duration {
+	return p.config.SaltRotation * time.Duration(p.retainedEpochs()+1)
+}
+
+func saltName(epoch int64) string {
+	return "ip:" + strconv.FormatInt(epoch, 10)
+}
+
+// hashIP returns a 128-bit HMAC-SHA256 of value, or "" for an empty value.
+func hashIP(salt []byte, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/iamgideonidoko/signet/internal/config"
+	"github.com/iamgideonidoko/signet/internal/repository"
+	"github.com/iamgideonidoko/signet/pkg/cache"
+	"github.com/iamgideonidoko/signet/pkg/logger"
+	"github.com/iamgideonidoko/signet/pkg/similarity"
+)
+
+// RetentionPurger enforces the retention policies. Each policy runs in
+// batches of BatchSize rows, one short statement per batch, so purging never
+// holds long locks on the tables identification writes to.
+type RetentionPurger struct {
+	repo   *repository.Repository
+	cache  *cache.Cache
+	config *config.RetentionConfig
+}
+
+// PurgeResult counts the rows affected by one purge.
+type PurgeResult struct {
+	SignalsMinimized       int64 `json:"signals_minimized"`
+	IdentificationsDeleted int64 `json:"identifications_deleted"`
+	VisitorsDeleted        int64 `json:"visitors_deleted"`
+}
+
+func NewRetentionPurger(repo *repository.Repository, cache *cache.Cache, cfg *config.RetentionConfig) *RetentionPurger {
+	return &RetentionPurger{
+		repo:   repo,
+		cache:  cache,
+		config: cfg,
+	}
+}
+
+// Enabled reports whether any retention policy is configured.
+func (p *RetentionPurger) Enabled() bool {
+	return p.config.RawSignalsDays > 0 || p.config.IdentificationDays > 0 || p.config.InactiveVisitorDays > 0
+}
+
+// Run purges once per Interval until ctx is cancelled.
+func (p *RetentionPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := p.Purge(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Retention purge failed", map[string]any{"error": err.Error()})
+		}
+		if result.SignalsMinimized+result.IdentificationsDeleted+result.VisitorsDeleted > 0 {
+			logger.Info("Retention purge finished", map[string]any{
+				"signals_minimized":       result.SignalsMinimized,
+				"identifications_deleted": result.IdentificationsDeleted,
+				"visitors_deleted":        result.VisitorsDeleted,
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge applies every configured policy until nothing is left to purge and
+// adds the counts to the retention metrics.
+func (p *RetentionPurger) Purge(ctx context.Context) (PurgeResult, error) {
+	var result PurgeResult
+
+	policies := []struct {
+		days   int
+		metric string
+		count  *int64
+		purge  func(context.Context, time.Time, int) (int64, error)
+	}{
+		{p.config.RawSignalsDays, "retention_signals_minimized", &result.SignalsMinimized, p.minimizeSignals},
+		{p.config.IdentificationDays, "retention_identifications_deleted", &result.IdentificationsDeleted, p.repo.DeleteIdentificationsBefore},
+		{p.config.InactiveVisitorDays, "retention_visitors_deleted", &result.VisitorsDeleted, p.deleteInactiveVisitors},
+	}
+
+	for _, policy := range policies {
+		if policy.days <= 0 {
+			continue
+		}
+		cutoff := time.Now().AddDate(0, 0, -policy.days)
+
+		for ctx.Err() == nil {
+			n, err := policy.purge(ctx, cutoff, p.config.BatchSize)
+			if n > 0 {
+				*policy.count += n
+				_ = p.cache.AddToMetric(ctx, policy.metric, n)
+			}
+			if err != nil {
+				return result, err
+			}
+			if n < int64(p.config.BatchSize) {
+				break
+			}
+		}
+	}
+
+	return result, ctx.Err()
+}
+
+// deleteInactiveVisitors deletes a batch of inactive visitors, then clears
+// their hardware hash mappings and velocity windows so a returning device
+// is not resolved to a visitor that no longer exists, and drops them from
+// the uniqueness counts. Cache failures are logged; the mappings expire
+// within the cache TTL regardless.
+func (p *RetentionPurger) deleteInactiveVisitors(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	deleted, err := p.repo.DeleteInactiveVisitors(ctx, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range deleted {
+		id := v.VisitorID.String()
+		for _, hash := range v.HardwareHashes {
+			if _, err := p.cache.DeleteVisitorID(ctx, hash, id); err != nil {
+				logger.Warn("Failed to clear cached visitor mapping", map[string]any{
+					"error":      err.Error(),
+					"visitor_id": id,
+				})
+			}
+		}
+		if _, err := forgetVelocity(ctx, p.cache, id, v.Subnets, v.HardwareHashes); err != nil {
+			logger.Warn("Failed to clear velocity counters", map[string]any{
+				"error":      err.Error(),
+				"visitor_id": id,
+			})
+		}
+		if _, err := forgetAnonymity(ctx, p.cache, id); err != nil {
+			logger.Warn("Failed to clear uniqueness count", map[string]any{
+				"error":      err.Error(),
+				"visitor_id": id,
+			})
+		}
+	}
+	return int64(len(deleted)), nil
+}
+
+// minimizeSignals backfills the feature keys of a batch of rows stored
+// before feature keys existed, then minimizes a batch, so old rows keep
+// what matching needs.
+func (p *RetentionPurger) minimizeSignals(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	unfeatured, err := p.repo.GetUnfeaturedIdentifications(ctx, cutoff, limit)
+	if err != nil {
+		return 0, err
+	}
+	features := make(map[uuid.UUID][]string, len(unfeatured))
+	for _, ident := range unfeatured {
+		features[ident.RequestID] = similarity.FeatureKeys(ident.Signals)
+	}
+	if err := p.repo.SetFeatures(ctx, features); err != nil {
+		return 0, err
+	}
+
+	return p.repo.MinimizeSignals(ctx, cutoff, limit)
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/iamgideonidoko/signet/pkg/botdetect"
+	"github.com/iamgideonidoko/signet/pkg/logger"
+	"github.com/iamgideonidoko/signet/pkg/trust"
+)
+
+// UpdateTrustScore recomputes a visitor's trust score from its stored
+// identifications and persists it.
+func (s *IdentificationService) UpdateTrustScore(ctx context.Context, visitorID uuid.UUID) (float64, error) {
+	inputs, err := s.repo.GetTrustInputs(ctx, visitorID, botdetect.TamperingReasons)
+	if err != nil {
+		return 0, err
+	}
+
+	score := trust.Score(*inputs)
+	if err := s.repo.UpdateTrustScore(ctx, visitorID, score); err != nil {
+		return 0, err
+	}
+	return score, nil
+}
+
+// RecomputeTrustScores rescores every visitor in batches, e.g. after the
+// scoring model changes. It returns the number of visitors updated.
+func (s *IdentificationService) RecomputeTrustScores(ctx context.Context, batchSize int) (int, error) {
+	updated := 0
+	after := uuid.Nil
+
+	for {
+		ids, err := s.repo.ListVisitorIDs(ctx, after, batchSize)
+		if err != nil {
+			return updated, err
+		}
+
+		for _, id := range ids {
+			if _, err := s.UpdateTrustScore(ctx, id); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+
+		if len(ids) < batchSize {
+			return updated, nil
+		}
+		after = ids[len(ids)-1]
+
+		logger.Info("Recomputed trust scores", map[string]any{"visitors": updated})
+	}
+}
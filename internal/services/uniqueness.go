@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/cache"
+	"github.com/iamgideonidoko/signet/pkg/entropy"
+	"github.com/iamgideonidoko/signet/pkg/similarity"
+)
+
+// anonymitySalt names the salt visitor IDs are hashed with before they
+// are counted.
+const anonymitySalt = "anonymity"
+
+// UniquenessTracker keeps Redis frequency counts of exact feature vectors
+// so each identification can be placed in the population without scanning
+// stored identifications: how many visitors share its vector, and how many
+// are in larger anonymity sets. Visitors are counted under a salted hash of
+// their ID, never the ID itself.
+type UniquenessTracker struct {
+	cache  *cache.Cache
+	saltMu sync.Mutex
+	salt   []byte
+}
+
+func NewUniquenessTracker(cache *cache.Cache) *UniquenessTracker {
+	return &UniquenessTracker{cache: cache}
+}
+
+// Track counts the visitor among those reporting hashedKeys, once, and
+// returns the vector's uniqueness. A visitor whose vector changed moves out
+// of the set it was counted in. Pass keys hashed with
+// similarity.HashFeatureKeys, so the counts reveal no signal values.
+func (u *UniquenessTracker) Track(ctx context.Context, visitorID string, hashedKeys []string) (*models.Uniqueness, error) {
+	salt, err := u.loadSalt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vector := similarity.FeatureSetHash(hashedKeys)
+	size, histogram, err := u.cache.RecordAnonymity(ctx, vector, anonymityMember(salt, visitorID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Uniqueness{
+		AnonymitySet:   size,
+		MoreUniqueThan: entropy.MoreUniqueThan(histogram, size),
+	}, nil
+}
+
+// loadSalt returns the member salt, creating it on first use. It is loaded
+// once per process.
+func (u *UniquenessTracker) loadSalt(ctx context.Context) ([]byte, error) {
+	u.saltMu.Lock()
+	defer u.saltMu.Unlock()
+
+	if u.salt != nil {
+		return u.salt, nil
+	}
+
+	candidate := make([]byte, 32)
+	if _, err := rand.Read(candidate); err != nil {
+		return nil, fmt.Errorf("failed to generate anonymity salt: %w", err)
+	}
+	salt, err := u.cache.GetOrCreateSalt(ctx, anonymitySalt, candidate, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load anonymity salt: %w", err)
+	}
+	u.salt = salt
+	return salt, nil
+}
+
+// anonymityMember is the pseudonym a visitor is counted under.
+func anonymityMember(salt []byte, visitorID string) string {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(visitorID))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// lookupAnonymityMember returns a visitor's pseudonym without creating the
+// salt. It is empty when no visitor was ever counted. Counts outlive
+// UNIQUENESS_ENABLED, so erasure, export and retention use it regardless.
+func lookupAnonymityMember(ctx context.Context, c *cache.Cache, visitorID string) (string, error) {
+	salts, err := c.GetSalts(ctx, []string{anonymitySalt})
+	if err != nil {
+		return "", err
+	}
+	if salts[0] == nil {
+		return "", nil
+	}
+	return anonymityMember(salts[0], visitorID), nil
+}
+
+// forgetAnonymity stops counting a visitor in the uniqueness population
+// and reports whether it was counted.
+func forgetAnonymity(ctx context.Context, c *cache.Cache, visitorID string) (bool, error) {
+	member, err := lookupAnonymityMember(ctx, c, visitorID)
+	if err != nil || member == "" {
+		return false, err
+	}
+	return c.ForgetAnonymity(ctx, member)
+}
+
+// anonymityEntry returns the uniqueness count entry of a visitor, or nil.
+func anonymityEntry(ctx context.Context, c *cache.Cache, visitorID string) (*cache.Entry, error) {
+	member, err := lookupAnonymityMember(ctx, c, visitorID)
+	if err != nil || member == "" {
+		return nil, err
+	}
+	return c.AnonymityEntry(ctx, member)
+}
+
+// trackUniqueness places a stored identification in the population,
+// hashing its feature keys with the feature hash key first, and stores the
+// result on the identification.
+func (s *IdentificationService) trackUniqueness(ctx context.Context, ident *models.Identification, keys []string) error {
+	key, err := s.featureHashKey(ctx)
+	if err != nil {
+		return err
+	}
+	hashed := similarity.HashFeatureKeys(key, keys)
+	uniqueness, err := s.uniqueness.Track(ctx, ident.VisitorID.String(), hashed)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetUniqueness(ctx, ident.RequestID, *uniqueness); err != nil {
+		return err
+	}
+	ident.Uniqueness = uniqueness
+	return nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/iamgideonidoko/signet/internal/config"
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/cache"
+)
+
+// Risk flags raised by the velocity checker.
+const (
+	FlagSubnetVisitorVelocity = "subnet_visitor_velocity"
+	FlagVisitorSubnetVelocity = "visitor_subnet_velocity"
+	FlagIdentificationBurst   = "identification_burst"
+	FlagHardwareVisitorChurn  = "hardware_visitor_churn"
+)
+
+// VelocityChecker keeps Redis-backed rolling-window counters per visitor,
+// subnet and hardware hash, and flags identifications that exceed the
+// configured limits. Device farms show up as many visitors per subnet or
+// hardware hash, credential stuffers as bursts and subnet hopping.
+type VelocityChecker struct {
+	cache  *cache.Cache
+	config *config.VelocityConfig
+}
+
+func NewVelocityChecker(cache *cache.Cache, cfg *config.VelocityConfig) *VelocityChecker {
+	return &VelocityChecker{
+		cache:  cache,
+		config: cfg,
+	}
+}
+
+// Check records the identification in every counter and returns the flags of
+// the rules it trips. Counter failures are ignored so Redis trouble degrades
+// detection rather than identification.
+func (v *VelocityChecker) Check(ctx context.Context, ident *models.Identification, subnet string, isNew bool) []string {
+	visitorID := ident.VisitorID.String()
+
+	rules := []struct {
+		flag   string
+		limit  int
+		key    string
+		member string
+		window time.Duration
+		skip   bool
+	}{
+		{FlagSubnetVisitorVelocity, v.config.MaxVisitorsPerSubnet,
+			"subnet_visitors:" + subnet, visitorID, v.config.Window, false},
+		{FlagVisitorSubnetVelocity, v.config.MaxSubnetsPerVisitor,
+			"visitor_subnets:" + visitorID, subnet, v.config.Window, false},
+		{FlagIdentificationBurst, v.config.MaxIdentificationsPerMinute,
+			"visitor_idents:" + visitorID, ident.RequestID.String(), time.Minute, false},
+		{FlagHardwareVisitorChurn, v.config.MaxNewVisitorsPerHardware,
+			"hw_new_visitors:" + ident.HardwareHash, visitorID, v.config.Window, !isNew || ident.HardwareHash == ""},
+	}
+
+	var flags []string
+	for _, rule := range rules {
+		if rule.limit <= 0 || rule.skip {
+			continue
+		}
+		count, err := v.cache.RecordInWindow(ctx, rule.key, rule.member, rule.window)
+		if err != nil {
+			continue
+		}
+		if count > int64(rule.limit) {
+			flags = append(flags, rule.flag)
+		}
+	}
+
+	if len(flags) > 0 {
+		_ = v.cache.IncrementMetric(ctx, "velocity_flagged")
+	}
+	return flags
+}
+
+// visitorWindows returns the windows keyed by a visitor.
+func visitorWindows(visitorID string) []string {
+	return []string{"visitor_subnets:" + visitorID, "visitor_idents:" + visitorID}
+}
+
+// Entries returns the windows keyed by a visitor, for access exports.
+func (v *VelocityChecker) Entries(ctx context.Context, visitorID string) ([]cache.Entry, error) {
+	var entries []cache.Entry
+	for _, key := range visitorWindows(visitorID) {
+		entry, err := v.cache.WindowEntry(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries, nil
+}
+
+// Forget removes an erased visitor from every counter it was recorded in and
+// returns the number of windows deleted outright.
+func (v *VelocityChecker) Forget(ctx context.Context, visitorID string, subnets, hardwareHashes []string) (int, error) {
+	return forgetVelocity(ctx, v.cache, visitorID, subnets, hardwareHashes)
+}
+
+// forgetVelocity is Forget for callers without a VelocityChecker, such as
+// the retention purger, which clears windows left from when checks ran.
+func forgetVelocity(ctx context.Context, c *cache.Cache, visitorID string, subnets, hardwareHashes []string) (int, error) {
+	deleted := 0
+	for _, key := range visitorWindows(visitorID) {
+		ok, err := c.DeleteWindow(ctx, key)
+		if err != nil {
+			return deleted, err
+		}
+		if ok {
+			deleted++
+		}
+	}
+
+	for _, subnet := range subnets {
+		if err := c.RemoveFromWindow(ctx, "subnet_visitors:"+subnet, visitorID); err != nil {
+			return deleted, err
+		}
+	}
+	for _, hash := range hardwareHashes {
+		if err := c.RemoveFromWindow(ctx, "hw_new_visitors:"+hash, visitorID); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_identifications_origin;
+
+DROP INDEX IF EXISTS idx_identifications_linked_id;
+
+DROP INDEX IF EXISTS idx_identifications_tag;
+
+ALTER TABLE IF EXISTS identifications
+  DROP COLUMN IF EXISTS origin,
+  DROP COLUMN IF EXISTS referrer,
+  DROP COLUMN IF EXISTS url,
+  DROP COLUMN IF EXISTS linked_id,
+  DROP COLUMN IF EXISTS tag;
@@ -0,0 +1,13 @@
+-- Description: Add caller-supplied request metadata to identifications
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS tag jsonb,
+  ADD COLUMN IF NOT EXISTS linked_id text,
+  ADD COLUMN IF NOT EXISTS url text,
+  ADD COLUMN IF NOT EXISTS referrer text,
+  ADD COLUMN IF NOT EXISTS origin text;
+
+CREATE INDEX IF NOT EXISTS idx_identifications_tag ON identifications USING GIN (tag);
+
+CREATE INDEX IF NOT EXISTS idx_identifications_linked_id ON identifications (linked_id);
+
+CREATE INDEX IF NOT EXISTS idx_identifications_origin ON identifications (origin);
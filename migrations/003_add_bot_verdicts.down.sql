@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_identifications_bot_verdict;
+
+ALTER TABLE IF EXISTS identifications
+  DROP COLUMN IF EXISTS bot_reasons,
+  DROP COLUMN IF EXISTS bot_score,
+  DROP COLUMN IF EXISTS bot_verdict;
@@ -0,0 +1,15 @@
+-- Description: Store scored bot verdicts on identifications
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS bot_verdict text NOT NULL DEFAULT 'human',
+  ADD COLUMN IF NOT EXISTS bot_score float NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS bot_reasons text[];
+
+UPDATE
+  identifications
+SET
+  bot_verdict = 'bad_bot',
+  bot_score = 1
+WHERE
+  is_bot;
+
+CREATE INDEX IF NOT EXISTS idx_identifications_bot_verdict ON identifications (bot_verdict);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_identifications_risk_flags;
+
+ALTER TABLE IF EXISTS identifications
+  DROP COLUMN IF EXISTS risk_flags;
@@ -0,0 +1,5 @@
+-- Description: Store velocity and anomaly risk flags on identifications
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS risk_flags text[];
+
+CREATE INDEX IF NOT EXISTS idx_identifications_risk_flags ON identifications USING GIN (risk_flags);
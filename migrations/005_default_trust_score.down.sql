@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS visitors
+  ALTER COLUMN trust_score SET DEFAULT 1.0;
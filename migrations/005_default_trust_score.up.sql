@@ -0,0 +1,3 @@
+-- Description: New visitors start at a neutral trust score
+ALTER TABLE visitors
+  ALTER COLUMN trust_score SET DEFAULT 0.5;
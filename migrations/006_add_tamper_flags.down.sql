@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS identifications
+  DROP COLUMN IF EXISTS tamper_flags;
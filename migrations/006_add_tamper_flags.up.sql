@@ -0,0 +1,3 @@
+-- Description: Store signal consistency findings on identifications
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS tamper_flags jsonb;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_identifications_ip_flags;
+
+ALTER TABLE IF EXISTS identifications
+  DROP COLUMN IF EXISTS ip_flags;
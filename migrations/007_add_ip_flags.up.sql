@@ -0,0 +1,5 @@
+-- Description: Store Tor, VPN, proxy and datacenter flags for the client IP
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS ip_flags text[];
+
+CREATE INDEX IF NOT EXISTS idx_identifications_ip_flags ON identifications USING GIN (ip_flags);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_identifications_geo_country;
+
+ALTER TABLE IF EXISTS identifications
+  DROP COLUMN IF EXISTS geo;
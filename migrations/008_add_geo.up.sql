@@ -0,0 +1,5 @@
+-- Description: Store coarse GeoIP results (country, region, city, ASN) on identifications
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS geo jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_identifications_geo_country ON identifications ((geo->>'country'), created_at DESC);
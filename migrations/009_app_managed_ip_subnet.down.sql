@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS identifications
+  DROP COLUMN IF EXISTS ip_subnet;
+
+ALTER TABLE IF EXISTS identifications
+  ADD COLUMN IF NOT EXISTS ip_subnet cidr GENERATED ALWAYS AS (host(ip_address)::inet & '255.255.255.0'::inet) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_identifications_ip_subnet ON identifications (ip_subnet);
@@ -0,0 +1,31 @@
+-- Description: Replace the IPv4-only generated ip_subnet with a column set by
+-- the application, so IPv6 subnets use the configured prefix length
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT
+      1
+    FROM
+      information_schema.columns
+    WHERE
+      table_name = 'identifications'
+      AND column_name = 'ip_subnet'
+      AND is_generated = 'ALWAYS') THEN
+  ALTER TABLE identifications
+    DROP COLUMN ip_subnet;
+END IF;
+END
+$$;
+
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS ip_subnet cidr;
+
+-- Backfill with the default prefixes (/24 for IPv4, /48 for IPv6)
+UPDATE
+  identifications
+SET
+  ip_subnet = network(set_masklen(ip_address, CASE WHEN family(ip_address) = 4 THEN 24 ELSE 48 END))
+WHERE
+  ip_subnet IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_identifications_ip_subnet ON identifications (ip_subnet);
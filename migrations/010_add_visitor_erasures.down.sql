@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS blocked_devices;
+
+DROP TABLE IF EXISTS visitor_erasures;
@@ -0,0 +1,17 @@
+-- Description: Audit trail for right-to-erasure requests and devices blocked from re-profiling
+CREATE TABLE IF NOT EXISTS visitor_erasures (
+  erasure_id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+  visitor_id uuid NOT NULL,
+  reason text,
+  identifications_deleted integer NOT NULL,
+  devices_blocked integer NOT NULL DEFAULT 0,
+  erased_at timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_visitor_erasures_visitor_id ON visitor_erasures (visitor_id);
+
+CREATE TABLE IF NOT EXISTS blocked_devices (
+  hardware_hash text PRIMARY KEY,
+  erasure_id uuid NOT NULL REFERENCES visitor_erasures (erasure_id),
+  created_at timestamp NOT NULL DEFAULT NOW()
+);
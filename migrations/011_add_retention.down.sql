@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_visitors_updated_at;
+
+DROP INDEX IF EXISTS idx_identifications_raw_signals;
+
+UPDATE
+  identifications
+SET
+  signals = '{}'::jsonb
+WHERE
+  signals IS NULL;
+
+ALTER TABLE IF EXISTS identifications
+  ALTER COLUMN signals SET NOT NULL;
+
+ALTER TABLE IF EXISTS identifications
+  DROP COLUMN IF EXISTS features;
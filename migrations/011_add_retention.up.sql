@@ -0,0 +1,14 @@
+-- Description: Store weight-independent feature keys so raw signals can be
+-- dropped after the retention period, and index the columns the purger scans
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS features text[];
+
+ALTER TABLE identifications
+  ALTER COLUMN signals DROP NOT NULL;
+
+-- Rows still holding raw signals, oldest first
+CREATE INDEX IF NOT EXISTS idx_identifications_raw_signals ON identifications (created_at)
+WHERE
+  signals IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_visitors_updated_at ON visitors (updated_at);
@@ -0,0 +1,5 @@
+-- Description: Record the consent state each identification was made under
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS consent text NOT NULL DEFAULT 'full';
+
+CREATE INDEX IF NOT EXISTS idx_identifications_consent ON identifications (consent);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_identifications_signals_key_id;
+
+DROP INDEX IF EXISTS idx_identifications_raw_signals;
+
+ALTER TABLE IF EXISTS identifications
+  DROP COLUMN IF EXISTS signals_ciphertext,
+  DROP COLUMN IF EXISTS signals_data_key,
+  DROP COLUMN IF EXISTS signals_key_id;
+
+CREATE INDEX IF NOT EXISTS idx_identifications_raw_signals ON identifications (created_at)
+WHERE
+  signals IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Description: Envelope-encrypted raw signals. The payload is encrypted with a
+-- per-row data key, wrapped by the master key named in signals_key_id
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS signals_ciphertext bytea,
+  ADD COLUMN IF NOT EXISTS signals_data_key bytea,
+  ADD COLUMN IF NOT EXISTS signals_key_id text;
+
+-- Rows still holding raw signals in either form, oldest first
+DROP INDEX IF EXISTS idx_identifications_raw_signals;
+
+CREATE INDEX IF NOT EXISTS idx_identifications_raw_signals ON identifications (created_at)
+WHERE
+  signals IS NOT NULL OR signals_ciphertext IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_identifications_signals_key_id ON identifications (signals_key_id)
+WHERE
+  signals_key_id IS NOT NULL;
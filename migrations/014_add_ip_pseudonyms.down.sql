@@ -0,0 +1,35 @@
+CREATE OR REPLACE FUNCTION update_visitor_timestamp ()
+  RETURNS TRIGGER
+  AS $$
+BEGIN
+  UPDATE
+    visitors
+  SET
+    updated_at = NOW(),
+    last_seen_ip = NEW.ip_address,
+    visit_count = visit_count + 1
+  WHERE
+    visitor_id = NEW.visitor_id;
+  RETURN NEW;
+END;
+$$
+LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_identifications_ip_subnet_hash;
+
+ALTER TABLE IF EXISTS visitors
+  DROP COLUMN IF EXISTS first_seen_ip_hash,
+  DROP COLUMN IF EXISTS last_seen_ip_hash;
+
+-- Pseudonymized rows have no address to restore
+UPDATE
+  identifications
+SET
+  ip_address = '0.0.0.0'
+WHERE
+  ip_address IS NULL;
+
+ALTER TABLE IF EXISTS identifications
+  ALTER COLUMN ip_address SET NOT NULL,
+  DROP COLUMN IF EXISTS ip_hash,
+  DROP COLUMN IF EXISTS ip_subnet_hash;
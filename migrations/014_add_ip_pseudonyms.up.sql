@@ -0,0 +1,32 @@
+-- Description: Keyed IP and subnet hashes, stored in place of the masked IP
+-- when pseudonymization is enabled
+ALTER TABLE identifications
+  ALTER COLUMN ip_address DROP NOT NULL,
+  ADD COLUMN IF NOT EXISTS ip_hash text,
+  ADD COLUMN IF NOT EXISTS ip_subnet_hash text;
+
+ALTER TABLE visitors
+  ADD COLUMN IF NOT EXISTS first_seen_ip_hash text,
+  ADD COLUMN IF NOT EXISTS last_seen_ip_hash text;
+
+CREATE INDEX IF NOT EXISTS idx_identifications_ip_subnet_hash ON identifications (ip_subnet_hash)
+WHERE
+  ip_subnet_hash IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION update_visitor_timestamp ()
+  RETURNS TRIGGER
+  AS $$
+BEGIN
+  UPDATE
+    visitors
+  SET
+    updated_at = NOW(),
+    last_seen_ip = NEW.ip_address,
+    last_seen_ip_hash = NEW.ip_hash,
+    visit_count = visit_count + 1
+  WHERE
+    visitor_id = NEW.visitor_id;
+  RETURN NEW;
+END;
+$$
+LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS identifications
+  DROP COLUMN IF EXISTS uniqueness_percentile,
+  DROP COLUMN IF EXISTS anonymity_set;
@@ -0,0 +1,5 @@
+-- Description: Anonymity set size and uniqueness percentile of each
+-- identification's feature vector at the time it was made
+ALTER TABLE identifications
+  ADD COLUMN IF NOT EXISTS anonymity_set integer,
+  ADD COLUMN IF NOT EXISTS uniqueness_percentile real;
@@ -0,0 +1,11 @@
+-- Description: Pairwise joint entropy and mutual information between signals,
+-- one row per run of the correlation job
+CREATE TABLE IF NOT EXISTS correlation_reports (
+  report_id uuid PRIMARY KEY DEFAULT uuid_generate_v4 (),
+  computed_at timestamp NOT NULL DEFAULT NOW(),
+  days integer NOT NULL,
+  population bigint NOT NULL,
+  report jsonb NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_correlation_reports_computed_at ON correlation_reports (computed_at DESC);
@@ -0,0 +1,14 @@
+#!/bin/sh
+# Runs last when the database container first initializes, after every
+# migration in this directory was applied, and records them so that
+# `make migrate` only applies newer ones.
+set -e
+
+psql -v ON_ERROR_STOP=1 -q --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" \
+	-c "CREATE TABLE IF NOT EXISTS schema_migrations (version text PRIMARY KEY, applied_at timestamp NOT NULL DEFAULT NOW())"
+
+for f in /docker-entrypoint-initdb.d/*.up.sql; do
+	v=$(basename "$f" .up.sql)
+	psql -v ON_ERROR_STOP=1 -q --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" \
+		-c "INSERT INTO schema_migrations (version) VALUES ('$v') ON CONFLICT DO NOTHING"
+done
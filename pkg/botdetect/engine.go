@@ -0,0 +1,107 @@
+// Package botdetect scores identification requests against a set of weighted
+// rules and turns the total into a verdict.
+package botdetect
+
+import (
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/useragent"
+)
+
+// Input is everything a rule may inspect.
+type Input struct {
+	Signals         models.Signals
+	Headers         models.RequestHeaders
+	UserAgent       useragent.Info // Parsed from Signals.UserAgent
+	HeaderUserAgent useragent.Info // Parsed from Headers.UserAgent
+
+	// Crawler is the crawler claimed by the User-Agent, if any, and
+	// CrawlerVerified whether the client IP is in its published ranges.
+	Crawler         string
+	CrawlerVerified bool
+
+	// IPFlags are the client IP's ipintel flags.
+	IPFlags []string
+}
+
+// NewInput parses the derived fields of an Input.
+func NewInput(signals models.Signals, headers models.RequestHeaders) Input {
+	return Input{
+		Signals:         signals,
+		Headers:         headers,
+		UserAgent:       useragent.Parse(signals.UserAgent),
+		HeaderUserAgent: useragent.Parse(headers.UserAgent),
+	}
+}
+
+// Rule contributes Weight to the score and its Code to the reasons when
+// Match returns true.
+type Rule struct {
+	Code   string
+	Weight float64
+	Match  func(Input) bool
+}
+
+// Config tunes the engine. Weights override rule defaults by code; rules
+// listed in Disabled are skipped entirely.
+type Config struct {
+	SuspectThreshold float64
+	BadThreshold     float64
+	Disabled         []string
+	Weights          map[string]float64
+}
+
+// DefaultConfig flags a single strong signal as a bad bot and two weak ones
+// as suspected.
+var DefaultConfig = Config{
+	SuspectThreshold: 0.5,
+	BadThreshold:     1.0,
+}
+
+// Engine evaluates rules and classifies the summed score.
+type Engine struct {
+	rules  []Rule
+	config Config
+}
+
+// NewEngine applies cfg to the rules and returns a ready engine.
+func NewEngine(rules []Rule, cfg Config) *Engine {
+	disabled := make(map[string]bool, len(cfg.Disabled))
+	for _, code := range cfg.Disabled {
+		disabled[code] = true
+	}
+
+	active := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if disabled[rule.Code] {
+			continue
+		}
+		if w, ok := cfg.Weights[rule.Code]; ok {
+			rule.Weight = w
+		}
+		active = append(active, rule)
+	}
+
+	return &Engine{rules: active, config: cfg}
+}
+
+// Evaluate scores the input. Reasons are reported in rule order.
+func (e *Engine) Evaluate(in Input) models.BotResult {
+	result := models.BotResult{Verdict: models.VerdictHuman}
+
+	for _, rule := range e.rules {
+		if rule.Weight <= 0 || !rule.Match(in) {
+			continue
+		}
+		result.Score += rule.Weight
+		result.Reasons = append(result.Reasons, rule.Code)
+	}
+
+	switch {
+	case result.Score >= e.config.BadThreshold:
+		result.Verdict = models.VerdictBadBot
+	case result.Score >= e.config.SuspectThreshold:
+		result.Verdict = models.VerdictSuspectedBot
+	}
+
+	return result
+}
@@ -0,0 +1,107 @@
+package botdetect
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/ipintel"
+)
+
+// browserHeaders returns the headers a real browser sends alongside its signals.
+func browserHeaders(signals models.Signals) models.RequestHeaders {
+	return models.RequestHeaders{UserAgent: signals.UserAgent, AcceptLanguage: "en-US,en;q=0.9"}
+}
+
+func humanSignals(ua string) models.Signals {
+	return models.Signals{
+		Canvas2DHash:        "abc123",
+		AudioHash:           "def456",
+		HardwareConcurrency: 8,
+		UserAgent:           ua,
+		Languages:           []string{"en-US", "en"},
+	}
+}
+
+const (
+	firefoxUA = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:121.0) Gecko/20100101 Firefox/121.0"
+	chromeUA  = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36"
+)
+
+func TestEvaluate_FirefoxWithoutDeviceMemoryIsHuman(t *testing.T) {
+	engine := NewEngine(DefaultRules(), DefaultConfig)
+
+	signals := humanSignals(firefoxUA)
+	result := engine.Evaluate(NewInput(signals, browserHeaders(signals)))
+
+	if result.Verdict != models.VerdictHuman || len(result.Reasons) != 0 {
+		t.Errorf("Expected human with no reasons, got %+v", result)
+	}
+}
+
+func TestEvaluate_Verdicts(t *testing.T) {
+	engine := NewEngine(DefaultRules(), DefaultConfig)
+
+	webdriver := humanSignals(chromeUA)
+	webdriver.DeviceMemory = 8
+	webdriver.WebDriver = true
+
+	emulated := humanSignals(chromeUA)
+	emulated.WebGLRenderer = "Google SwiftShader"
+
+	tests := []struct {
+		name    string
+		signals models.Signals
+		verdict models.BotVerdict
+		reasons []string
+	}{
+		{"webdriver", webdriver, models.VerdictBadBot, []string{ReasonWebDriver}},
+		{"chrome without memory on swiftshader", emulated, models.VerdictSuspectedBot,
+			[]string{ReasonNoDeviceMemory, ReasonSoftwareRenderer}},
+	}
+
+	for _, tt := range tests {
+		result := engine.Evaluate(NewInput(tt.signals, browserHeaders(tt.signals)))
+		if result.Verdict != tt.verdict || !reflect.DeepEqual(result.Reasons, tt.reasons) {
+			t.Errorf("%s: got %s %v, want %s %v", tt.name, result.Verdict, result.Reasons, tt.verdict, tt.reasons)
+		}
+	}
+}
+
+func TestEvaluate_IPFlags(t *testing.T) {
+	engine := NewEngine(DefaultRules(), DefaultConfig)
+
+	signals := humanSignals(firefoxUA)
+	input := NewInput(signals, browserHeaders(signals))
+
+	input.IPFlags = []string{ipintel.FlagVPN}
+	if result := engine.Evaluate(input); result.Verdict != models.VerdictHuman {
+		t.Errorf("VPN alone: got %s, want human", result.Verdict)
+	}
+
+	input.IPFlags = []string{ipintel.FlagTor, ipintel.FlagDatacenter}
+	result := engine.Evaluate(input)
+	expected := []string{ReasonTorExit, ReasonDatacenter}
+	if result.Verdict != models.VerdictSuspectedBot || !reflect.DeepEqual(result.Reasons, expected) {
+		t.Errorf("Tor from a datacenter: got %s %v, want suspected_bot %v", result.Verdict, result.Reasons, expected)
+	}
+}
+
+func TestNewEngine_ConfigOverrides(t *testing.T) {
+	signals := humanSignals(chromeUA)
+	signals.DeviceMemory = 8
+	signals.WebDriver = true
+	signals.HeadlessChrome = true
+
+	engine := NewEngine(DefaultRules(), Config{
+		SuspectThreshold: 0.5,
+		BadThreshold:     1.0,
+		Disabled:         []string{ReasonWebDriver},
+		Weights:          map[string]float64{ReasonHeadlessChrome: 0.6},
+	})
+
+	result := engine.Evaluate(NewInput(signals, browserHeaders(signals)))
+	if result.Verdict != models.VerdictSuspectedBot || result.Score != 0.6 {
+		t.Errorf("Expected suspected bot with score 0.6, got %+v", result)
+	}
+}
@@ -0,0 +1,133 @@
+package botdetect
+
+import (
+	"strings"
+
+	"github.com/iamgideonidoko/signet/pkg/useragent"
+)
+
+// TamperingReasons are the reason codes that indicate forged or replayed
+// signals rather than automation as such.
+var TamperingReasons = []string{
+	ReasonUAHeaderMismatch,
+	ReasonUAHeaderFamilyMismatch,
+	ReasonAcceptLanguageMismatch,
+	ReasonClientHintsUnexpected,
+	ReasonClientHintsBrowser,
+	ReasonClientHintsPlatform,
+	ReasonClientHintsMobile,
+}
+
+// HeaderRules compare the HTTP request headers with the signals collected by
+// the agent. A replayed payload sent from a different HTTP client carries the
+// original browser's signals but the replaying client's headers.
+func HeaderRules() []Rule {
+	return []Rule{
+		{Code: ReasonUAHeaderMissing, Weight: 0.5, Match: func(in Input) bool {
+			return in.Headers.UserAgent == "" && in.Signals.UserAgent != ""
+		}},
+		{Code: ReasonUAHeaderFamilyMismatch, Weight: 1.0, Match: func(in Input) bool {
+			return in.Headers.UserAgent != "" && in.Signals.UserAgent != "" &&
+				(in.HeaderUserAgent.Browser != in.UserAgent.Browser || in.HeaderUserAgent.OS != in.UserAgent.OS)
+		}},
+		{Code: ReasonUAHeaderMismatch, Weight: 0.3, Match: func(in Input) bool {
+			return in.Headers.UserAgent != "" && in.Signals.UserAgent != "" &&
+				in.Headers.UserAgent != in.Signals.UserAgent &&
+				in.HeaderUserAgent.Browser == in.UserAgent.Browser && in.HeaderUserAgent.OS == in.UserAgent.OS
+		}},
+		{Code: ReasonAcceptLanguageMissing, Weight: 0.3, Match: func(in Input) bool {
+			return in.Headers.AcceptLanguage == "" && len(in.Signals.Languages) > 0
+		}},
+		{Code: ReasonAcceptLanguageMismatch, Weight: 0.4, Match: func(in Input) bool {
+			if in.Headers.AcceptLanguage == "" || len(in.Signals.Languages) == 0 {
+				return false
+			}
+			return baseLanguage(PrimaryLanguage(in.Headers.AcceptLanguage)) != baseLanguage(in.Signals.Languages[0])
+		}},
+		// Only Chromium browsers send client hints.
+		{Code: ReasonClientHintsUnexpected, Weight: 0.6, Match: func(in Input) bool {
+			return in.Headers.SecCHUA != "" && in.UserAgent.Browser != useragent.Unknown && !in.UserAgent.IsChromium()
+		}},
+		{Code: ReasonClientHintsBrowser, Weight: 0.6, Match: clientHintsBrowserMismatch},
+		{Code: ReasonClientHintsPlatform, Weight: 0.6, Match: func(in Input) bool {
+			platform := strings.Trim(in.Headers.SecCHUAPlatform, `"`)
+			return platform != "" && in.UserAgent.OS != useragent.Unknown && platform != in.UserAgent.OS
+		}},
+		{Code: ReasonClientHintsMobile, Weight: 0.4, Match: func(in Input) bool {
+			switch in.Headers.SecCHUAMobile {
+			case "?1":
+				return !in.UserAgent.Mobile
+			case "?0":
+				return in.UserAgent.Mobile
+			}
+			return false
+		}},
+	}
+}
+
+// brandByBrowser maps a UA family to the Sec-CH-UA brand it must advertise.
+var brandByBrowser = map[string]string{
+	useragent.Chrome: "Google Chrome",
+	useragent.Edge:   "Microsoft Edge",
+	useragent.Opera:  "Opera",
+}
+
+// clientHintsBrowserMismatch flags Sec-CH-UA brands that disagree with the
+// claimed browser or its major version.
+func clientHintsBrowserMismatch(in Input) bool {
+	if in.Headers.SecCHUA == "" || !in.UserAgent.IsChromium() {
+		return false
+	}
+
+	brands := ParseClientHintBrands(in.Headers.SecCHUA)
+	if version, ok := brands["Chromium"]; ok && in.UserAgent.BrowserVersion != "" &&
+		(in.UserAgent.Browser == useragent.Chrome || in.UserAgent.Browser == useragent.Edge) &&
+		version != in.UserAgent.BrowserVersion {
+		return true
+	}
+
+	// Plain Chromium builds advertise only "Chromium", so a missing vendor
+	// brand is tolerated; a different vendor brand is not.
+	want := brandByBrowser[in.UserAgent.Browser]
+	if _, ok := brands[want]; want == "" || ok {
+		return false
+	}
+	for _, brand := range brandByBrowser {
+		if _, ok := brands[brand]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseClientHintBrands parses a Sec-CH-UA header such as
+// `"Chromium";v="120", "Google Chrome";v="120"` into brand → major version.
+func ParseClientHintBrands(header string) map[string]string {
+	brands := make(map[string]string)
+	for _, entry := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		name = strings.Trim(name, `"`)
+		if name == "" {
+			continue
+		}
+		version := ""
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "v="); ok {
+			version = strings.Trim(v, `"`)
+		}
+		brands[name] = version
+	}
+	return brands
+}
+
+// PrimaryLanguage returns the first language tag of an Accept-Language header.
+func PrimaryLanguage(header string) string {
+	first, _, _ := strings.Cut(header, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	return strings.TrimSpace(tag)
+}
+
+// baseLanguage reduces "en-US" to "en" so regional variants still match.
+func baseLanguage(tag string) string {
+	base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	return base
+}
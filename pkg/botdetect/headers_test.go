@@ -0,0 +1,71 @@
+package botdetect
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+)
+
+func TestHeaderRules(t *testing.T) {
+	engine := NewEngine(HeaderRules(), DefaultConfig)
+
+	chrome := humanSignals(chromeUA)
+	chromeHints := models.RequestHeaders{
+		UserAgent:       chromeUA,
+		AcceptLanguage:  "en-US,en;q=0.9",
+		SecCHUA:         `"Not_A Brand";v="8", "Chromium";v="120", "Google Chrome";v="120"`,
+		SecCHUAPlatform: `"Windows"`,
+		SecCHUAMobile:   "?0",
+	}
+
+	replayed := chromeHints
+	replayed.UserAgent = "python-requests/2.31.0"
+	replayed.SecCHUA = ""
+
+	wrongLanguage := chromeHints
+	wrongLanguage.AcceptLanguage = "de-DE,de;q=0.9"
+
+	spoofedPlatform := chromeHints
+	spoofedPlatform.SecCHUAPlatform = `"Linux"`
+	spoofedPlatform.SecCHUA = `"Chromium";v="118", "Google Chrome";v="118"`
+
+	tests := []struct {
+		name    string
+		signals models.Signals
+		headers models.RequestHeaders
+		reasons []string
+	}{
+		{"consistent chrome", chrome, chromeHints, nil},
+		{"regional language variant", chrome, func() models.RequestHeaders {
+			h := chromeHints
+			h.AcceptLanguage = "en-GB"
+			return h
+		}(), nil},
+		{"replayed from script", chrome, replayed, []string{ReasonUAHeaderFamilyMismatch}},
+		{"language mismatch", chrome, wrongLanguage, []string{ReasonAcceptLanguageMismatch}},
+		{"spoofed platform and version", chrome, spoofedPlatform,
+			[]string{ReasonClientHintsBrowser, ReasonClientHintsPlatform}},
+		{"client hints from firefox", humanSignals(firefoxUA), models.RequestHeaders{
+			UserAgent:      firefoxUA,
+			AcceptLanguage: "en-US",
+			SecCHUA:        chromeHints.SecCHUA,
+		}, []string{ReasonClientHintsUnexpected}},
+	}
+
+	for _, tt := range tests {
+		result := engine.Evaluate(NewInput(tt.signals, tt.headers))
+		if !reflect.DeepEqual(result.Reasons, tt.reasons) {
+			t.Errorf("%s: got reasons %v, want %v", tt.name, result.Reasons, tt.reasons)
+		}
+	}
+}
+
+func TestParseClientHintBrands(t *testing.T) {
+	brands := ParseClientHintBrands(`"Not_A Brand";v="8", "Chromium";v="120", "Microsoft Edge";v="120"`)
+
+	expected := map[string]string{"Not_A Brand": "8", "Chromium": "120", "Microsoft Edge": "120"}
+	if !reflect.DeepEqual(brands, expected) {
+		t.Errorf("ParseClientHintBrands() = %v, want %v", brands, expected)
+	}
+}
@@ -0,0 +1,94 @@
+package botdetect
+
+import (
+	"slices"
+
+	"github.com/iamgideonidoko/signet/pkg/ipintel"
+)
+
+// Reason codes reported by the default rules.
+const (
+	ReasonWebDriver        = "webdriver"
+	ReasonSelenium         = "selenium"
+	ReasonPhantom          = "phantomjs"
+	ReasonAutomation       = "automation_api"
+	ReasonHeadlessChrome   = "headless_chrome"
+	ReasonCanvasMissing    = "canvas_missing"
+	ReasonAudioMissing     = "audio_missing"
+	ReasonNoConcurrency    = "hardware_concurrency_missing"
+	ReasonNoDeviceMemory   = "device_memory_missing"
+	ReasonSoftwareRenderer = "software_renderer"
+	ReasonCrawlerImpostor  = "crawler_impostor"
+	ReasonVerifiedCrawler  = "verified_crawler"
+	ReasonTorExit          = "tor_exit_node"
+	ReasonVPN              = "vpn_ip"
+	ReasonProxy            = "proxy_ip"
+	ReasonDatacenter       = "datacenter_ip"
+
+	ReasonUAHeaderMissing        = "ua_header_missing"
+	ReasonUAHeaderMismatch       = "ua_header_mismatch"
+	ReasonUAHeaderFamilyMismatch = "ua_header_family_mismatch"
+	ReasonAcceptLanguageMissing  = "accept_language_missing"
+	ReasonAcceptLanguageMismatch = "accept_language_mismatch"
+	ReasonClientHintsUnexpected  = "client_hints_unexpected"
+	ReasonClientHintsBrowser     = "client_hints_browser_mismatch"
+	ReasonClientHintsPlatform    = "client_hints_platform_mismatch"
+	ReasonClientHintsMobile      = "client_hints_mobile_mismatch"
+)
+
+// DefaultRules returns the built-in rule set. Automation globals are
+// conclusive on their own; missing or emulated hardware is only suggestive.
+func DefaultRules() []Rule {
+	return append([]Rule{
+		{Code: ReasonWebDriver, Weight: 1.0, Match: func(in Input) bool {
+			return in.Signals.WebDriver
+		}},
+		{Code: ReasonSelenium, Weight: 1.0, Match: func(in Input) bool {
+			return in.Signals.SeleniumPresent
+		}},
+		{Code: ReasonPhantom, Weight: 1.0, Match: func(in Input) bool {
+			return in.Signals.PhantomPresent
+		}},
+		{Code: ReasonAutomation, Weight: 1.0, Match: func(in Input) bool {
+			return in.Signals.AutomationPresent
+		}},
+		{Code: ReasonHeadlessChrome, Weight: 1.0, Match: func(in Input) bool {
+			return in.Signals.HeadlessChrome
+		}},
+		{Code: ReasonCanvasMissing, Weight: 0.5, Match: func(in Input) bool {
+			return in.Signals.Canvas2DHash == "" || in.Signals.Canvas2DHash == "error"
+		}},
+		{Code: ReasonAudioMissing, Weight: 0.3, Match: func(in Input) bool {
+			return in.Signals.AudioHash == "" || in.Signals.AudioHash == "error"
+		}},
+		{Code: ReasonNoConcurrency, Weight: 0.5, Match: func(in Input) bool {
+			return in.Signals.HardwareConcurrency == 0
+		}},
+		// Only Chromium exposes navigator.deviceMemory; Firefox and Safari
+		// always report 0.
+		{Code: ReasonNoDeviceMemory, Weight: 0.3, Match: func(in Input) bool {
+			return in.Signals.DeviceMemory == 0 && in.UserAgent.IsChromium()
+		}},
+		{Code: ReasonSoftwareRenderer, Weight: 0.6, Match: func(in Input) bool {
+			return in.Signals.WebGLVendor == "Brian Paul" ||
+				in.Signals.WebGLRenderer == "Google SwiftShader"
+		}},
+		{Code: ReasonCrawlerImpostor, Weight: 1.0, Match: func(in Input) bool {
+			return in.Crawler != "" && !in.CrawlerVerified
+		}},
+		// Anonymizing networks are used by real people too; they only tip
+		// the verdict together with other signals.
+		{Code: ReasonTorExit, Weight: 0.3, Match: func(in Input) bool {
+			return slices.Contains(in.IPFlags, ipintel.FlagTor)
+		}},
+		{Code: ReasonVPN, Weight: 0.2, Match: func(in Input) bool {
+			return slices.Contains(in.IPFlags, ipintel.FlagVPN)
+		}},
+		{Code: ReasonProxy, Weight: 0.3, Match: func(in Input) bool {
+			return slices.Contains(in.IPFlags, ipintel.FlagProxy)
+		}},
+		{Code: ReasonDatacenter, Weight: 0.4, Match: func(in Input) bool {
+			return slices.Contains(in.IPFlags, ipintel.FlagDatacenter)
+		}},
+	}, HeaderRules()...)
+}
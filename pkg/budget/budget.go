@@ -0,0 +1,250 @@
+// Package budget limits which signal groups may identify a visitor, per
+// origin or API key, and caps the estimated entropy they add up to.
+// Disallowed signals are cleared before features are extracted or anything
+// is stored.
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/similarity"
+)
+
+// group is a set of signals that are allowed or cleared together. Bits is
+// the estimated entropy the group contributes.
+type group struct {
+	name    string
+	bits    float64
+	present func(models.Signals) bool
+	clear   func(*models.Signals)
+}
+
+// groups lists every signal group in default priority order: when a budget
+// allows all groups, the most stable identifiers are spent first. Entropy
+// estimates follow published browser fingerprinting studies.
+var groups = []group{
+	{"canvas", 8.0,
+		func(s models.Signals) bool { return s.Canvas2DHash != "" },
+		func(s *models.Signals) { s.Canvas2DHash, s.CanvasWinding = "", false }},
+	{"webgl", 6.5,
+		func(s models.Signals) bool { return s.WebGLVendor != "" || s.WebGLRenderer != "" || s.WebGLHash != "" },
+		func(s *models.Signals) {
+			s.WebGLVendor, s.WebGLRenderer, s.WebGLHash, s.WebGLParams = "", "", "", nil
+		}},
+	{"audio", 5.0,
+		func(s models.Signals) bool { return s.AudioHash != "" || s.AudioContextHash != "" },
+		func(s *models.Signals) { s.AudioHash, s.AudioContextHash = "", "" }},
+	{"fonts", 7.0,
+		func(s models.Signals) bool { return len(s.Fonts) > 0 },
+		func(s *models.Signals) { s.Fonts = nil }},
+	{"webgl_ext", 4.0,
+		func(s models.Signals) bool { return len(s.WebGLExtensions) > 0 },
+		func(s *models.Signals) { s.WebGLExtensions = nil }},
+	{"screen", 4.5,
+		func(s models.Signals) bool { return s.ScreenWidth > 0 || s.ScreenHeight > 0 },
+		func(s *models.Signals) {
+			s.ScreenWidth, s.ScreenHeight, s.AvailWidth, s.AvailHeight, s.MaxTouchPoints = 0, 0, 0, 0, 0
+		}},
+	{"hw_concurrency", 2.0,
+		func(s models.Signals) bool { return s.HardwareConcurrency > 0 },
+		func(s *models.Signals) { s.HardwareConcurrency = 0 }},
+	{"device_memory", 1.5,
+		func(s models.Signals) bool { return s.DeviceMemory > 0 },
+		func(s *models.Signals) { s.DeviceMemory = 0 }},
+	{"color_depth", 1.0,
+		func(s models.Signals) bool { return s.ColorDepth > 0 || s.PixelRatio > 0 || s.ColorGamut != "" },
+		func(s *models.Signals) { s.ColorDepth, s.PixelRatio, s.ColorGamut, s.HDRCapable = 0, 0, "", false }},
+	{"tz", 3.0,
+		func(s models.Signals) bool { return s.TimeZone != "" },
+		func(s *models.Signals) { s.TimeZone, s.TimezoneOffset = "", 0 }},
+	{"lang", 3.5,
+		func(s models.Signals) bool { return len(s.Languages) > 0 },
+		func(s *models.Signals) { s.Languages = nil }},
+	{"platform", 1.5,
+		func(s models.Signals) bool { return s.Platform != "" || s.Vendor != "" },
+		func(s *models.Signals) { s.Platform, s.Vendor = "", "" }},
+	{"browser", 3.5,
+		func(s models.Signals) bool { return s.UserAgent != "" },
+		func(s *models.Signals) { s.UserAgent = "" }},
+	{"advanced", 4.0,
+		func(s models.Signals) bool {
+			return len(s.Plugins) > 0 || s.MediaDevices > 0 || s.BatteryPresent || s.PermissionsHash != ""
+		},
+		func(s *models.Signals) {
+			s.Plugins, s.MediaDevices, s.BatteryPresent, s.PermissionsHash = nil, 0, false, ""
+		}},
+}
+
+// hardwareGroups are the groups similarity.ComputeHardwareHash covers.
+var hardwareGroups = []string{"canvas", "webgl", "audio", "hw_concurrency", "device_memory"}
+
+func lookup(name string) (group, bool) {
+	for _, g := range groups {
+		if g.name == name {
+			return g, true
+		}
+	}
+	return group{}, false
+}
+
+// Groups returns the names of all signal groups.
+func Groups() []string {
+	names := make([]string, len(groups))
+	for i, g := range groups {
+		names[i] = g.name
+	}
+	return names
+}
+
+// Budget restricts the signals used for one origin or API key. Groups are
+// spent in the order listed until MaxBits would be exceeded; groups that do
+// not fit are skipped. No groups allows all, and MaxBits 0 is unlimited.
+type Budget struct {
+	Groups  []string `json:"groups"`
+	MaxBits float64  `json:"max_bits"`
+}
+
+// Validate checks that every group exists.
+func (b *Budget) Validate() error {
+	for _, name := range b.Groups {
+		if _, ok := lookup(name); !ok {
+			return fmt.Errorf("budget: unknown signal group %q", name)
+		}
+	}
+	if b.MaxBits < 0 {
+		return fmt.Errorf("budget: max_bits must not be negative")
+	}
+	return nil
+}
+
+// Usage reports the signal groups an identification used and their
+// estimated entropy. Cleared lists the groups a budget cleared, whose
+// feature keys are left out. HardwareHash is empty when the budget cleared
+// a hardware group the request had: the hash of what is left would be
+// shared by every visitor under the same budget.
+type Usage struct {
+	Groups       []string
+	Bits         float64
+	Cleared      []string
+	HardwareHash string
+}
+
+// Apply returns signals with every group outside the budget cleared. A nil
+// budget allows every group and leaves signals untouched. Groups without
+// values are neither used nor charged.
+func (b *Budget) Apply(signals models.Signals) (models.Signals, Usage) {
+	allowed := groups
+	var maxBits float64
+	if b != nil {
+		maxBits = b.MaxBits
+		if len(b.Groups) > 0 {
+			allowed = make([]group, 0, len(b.Groups))
+			for _, name := range b.Groups {
+				if g, ok := lookup(name); ok {
+					allowed = append(allowed, g)
+				}
+			}
+		}
+	}
+
+	used := make(map[string]bool, len(allowed))
+	var usage Usage
+	for _, g := range allowed {
+		if used[g.name] || !g.present(signals) {
+			continue
+		}
+		if maxBits > 0 && usage.Bits+g.bits > maxBits {
+			continue
+		}
+		used[g.name] = true
+		usage.Groups = append(usage.Groups, g.name)
+		usage.Bits += g.bits
+	}
+
+	hardware := true
+	for _, name := range hardwareGroups {
+		if g, _ := lookup(name); g.present(signals) && !used[name] {
+			hardware = false
+		}
+	}
+
+	if b != nil {
+		for _, g := range groups {
+			if !used[g.name] {
+				g.clear(&signals)
+				usage.Cleared = append(usage.Cleared, g.name)
+			}
+		}
+	}
+	if hardware {
+		usage.HardwareHash = similarity.ComputeHardwareHash(signals)
+	}
+	return signals, usage
+}
+
+// Policy maps origins and API keys to budgets. An API key budget takes
+// precedence over an origin budget, which takes precedence over Default.
+type Policy struct {
+	Default *Budget            `json:"default"`
+	Origins map[string]*Budget `json:"origins"`
+	APIKeys map[string]*Budget `json:"api_keys"`
+}
+
+// Load reads a policy from a JSON file.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("budget: failed to read policy: %w", err)
+	}
+
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("budget: failed to parse policy: %w", err)
+	}
+
+	origins := make(map[string]*Budget, len(p.Origins))
+	for origin, b := range p.Origins {
+		origins[normalizeOrigin(origin)] = b
+	}
+	p.Origins = origins
+
+	for _, b := range p.budgets() {
+		if b == nil {
+			continue
+		}
+		if err := b.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+func (p *Policy) budgets() []*Budget {
+	all := []*Budget{p.Default}
+	for _, b := range p.Origins {
+		all = append(all, b)
+	}
+	for _, b := range p.APIKeys {
+		all = append(all, b)
+	}
+	return all
+}
+
+// For returns the budget for a request, or nil when none applies.
+func (p *Policy) For(origin, apiKey string) *Budget {
+	if b, ok := p.APIKeys[apiKey]; ok && apiKey != "" {
+		return b
+	}
+	if b, ok := p.Origins[normalizeOrigin(origin)]; ok && origin != "" {
+		return b
+	}
+	return p.Default
+}
+
+func normalizeOrigin(origin string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+}
@@ -0,0 +1,169 @@
+package budget
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/similarity"
+)
+
+func testSignals() models.Signals {
+	return models.Signals{
+		Canvas2DHash:        "abc123",
+		AudioHash:           "def456",
+		WebGLVendor:         "NVIDIA",
+		WebGLRenderer:       "GeForce GTX 1080",
+		HardwareConcurrency: 8,
+		ScreenWidth:         1920,
+		ScreenHeight:        1080,
+		TimeZone:            "Europe/Berlin",
+		Languages:           []string{"de-DE", "en"},
+		Fonts:               []string{"Arial"},
+		Platform:            "Win32",
+		UserAgent:           "Mozilla/5.0 Chrome/120.0.0.0",
+		WebDriver:           true,
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		budget   *Budget
+		expected []string
+	}{
+		{"nil budget uses everything present", nil,
+			[]string{"canvas", "webgl", "audio", "fonts", "screen", "hw_concurrency", "tz", "lang", "platform", "browser"}},
+		{"allowed groups only", &Budget{Groups: []string{"tz", "lang", "device_memory"}}, []string{"tz", "lang"}},
+		{"max bits skips groups that do not fit", &Budget{Groups: []string{"tz", "canvas", "lang", "platform"}, MaxBits: 8},
+			[]string{"tz", "lang", "platform"}},
+	}
+
+	for _, tt := range tests {
+		signals, usage := tt.budget.Apply(testSignals())
+		if !slices.Equal(usage.Groups, tt.expected) {
+			t.Errorf("%s: used %v, want %v", tt.name, usage.Groups, tt.expected)
+		}
+		if tt.budget != nil && tt.budget.MaxBits > 0 && usage.Bits > tt.budget.MaxBits {
+			t.Errorf("%s: spent %.1f bits over budget %.1f", tt.name, usage.Bits, tt.budget.MaxBits)
+		}
+		if !signals.WebDriver {
+			t.Errorf("%s: bot detection signals must not be cleared", tt.name)
+		}
+		if tt.budget == nil && len(usage.Cleared) > 0 {
+			t.Errorf("%s: cleared %v without a budget", tt.name, usage.Cleared)
+		}
+		for _, name := range usage.Cleared {
+			if slices.Contains(usage.Groups, name) {
+				t.Errorf("%s: group %s both used and cleared", tt.name, name)
+			}
+		}
+	}
+}
+
+func TestApply_ClearsDisallowedSignals(t *testing.T) {
+	signals, _ := (&Budget{Groups: []string{"tz"}}).Apply(testSignals())
+
+	if signals.Canvas2DHash != "" || signals.WebGLRenderer != "" || signals.UserAgent != "" || len(signals.Fonts) > 0 {
+		t.Errorf("Disallowed signals kept: %+v", signals)
+	}
+	if signals.TimeZone != "Europe/Berlin" {
+		t.Errorf("Allowed timezone cleared")
+	}
+}
+
+func TestApply_HardwareHash(t *testing.T) {
+	a := testSignals()
+	b := testSignals()
+	b.Canvas2DHash, b.AudioHash, b.WebGLRenderer, b.HardwareConcurrency = "zzz999", "yyy888", "Radeon RX 6800", 16
+
+	tests := []struct {
+		name   string
+		budget *Budget
+		keep   bool
+	}{
+		{"nil budget", nil, true},
+		{"all hardware groups allowed", &Budget{Groups: []string{"canvas", "webgl", "audio", "hw_concurrency", "device_memory", "tz"}}, true},
+		{"hardware groups cleared", &Budget{Groups: []string{"tz", "lang"}}, false},
+		{"hardware group over max bits", &Budget{Groups: []string{"canvas", "webgl", "audio", "hw_concurrency"}, MaxBits: 16}, false},
+	}
+
+	for _, tt := range tests {
+		_, usageA := tt.budget.Apply(a)
+		_, usageB := tt.budget.Apply(b)
+
+		if usageA.HardwareHash != "" && usageA.HardwareHash == usageB.HardwareHash {
+			t.Errorf("%s: different visitors share hardware hash %s", tt.name, usageA.HardwareHash)
+		}
+		if (usageA.HardwareHash != "") != tt.keep {
+			t.Errorf("%s: hardware hash %q, want kept=%v", tt.name, usageA.HardwareHash, tt.keep)
+		}
+	}
+}
+
+func TestApply_DisjointVisitorsDoNotMatch(t *testing.T) {
+	a := testSignals()
+	b := models.Signals{
+		Canvas2DHash:        "zzz999",
+		AudioHash:           "yyy888",
+		WebGLVendor:         "AMD",
+		WebGLRenderer:       "Radeon RX 6800",
+		HardwareConcurrency: 16,
+		ScreenWidth:         2560,
+		ScreenHeight:        1440,
+		TimeZone:            "Asia/Tokyo",
+		Languages:           []string{"ja-JP"},
+		Fonts:               []string{"Meiryo"},
+		Platform:            "MacIntel",
+		UserAgent:           "Mozilla/5.0 Firefox/121.0",
+	}
+
+	calc := similarity.NewCalculator(similarity.DefaultWeights)
+	budget := &Budget{Groups: []string{"tz", "lang"}}
+	signalsA, usageA := budget.Apply(a)
+	signalsB, usageB := budget.Apply(b)
+
+	score := calc.JaccardSimilarity(
+		calc.Vector(similarity.WithoutCategories(similarity.FeatureKeys(signalsA), usageA.Cleared)),
+		calc.Vector(similarity.WithoutCategories(similarity.FeatureKeys(signalsB), usageB.Cleared)),
+	)
+	if score > 0.01 {
+		t.Errorf("Disjoint visitors under a budget scored %.3f, want ~0", score)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budgets.json")
+	policy := `{
+		"default": {"max_bits": 20},
+		"origins": {"https://Shop.example/": {"groups": ["tz", "lang"]}},
+		"api_keys": {"pk_low": {"groups": ["screen"], "max_bits": 5}}
+	}`
+	if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if b := p.For("https://shop.example", ""); b == nil || !slices.Equal(b.Groups, []string{"tz", "lang"}) {
+		t.Errorf("For(origin) = %+v", b)
+	}
+	if b := p.For("https://shop.example", "pk_low"); b == nil || b.MaxBits != 5 {
+		t.Errorf("For(api key) = %+v", b)
+	}
+	if b := p.For("https://other.example", ""); b != p.Default {
+		t.Errorf("For(unknown origin) = %+v, want default", b)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"origins": {"https://a.example": {"groups": ["gait"]}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for unknown group")
+	}
+}
@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -13,6 +14,14 @@ type Cache struct {
 	ttl    time.Duration
 }
 
+// Entry is the raw content of a key, for access exports. ExpiresIn is
+// negative for keys without an expiry.
+type Entry struct {
+	Key       string
+	Values    []string
+	ExpiresIn time.Duration
+}
+
 func NewCache(url string, ttl time.Duration) (*Cache, error) {
 	opts, err := redis.ParseURL(url)
 	if err != nil {
@@ -56,6 +65,46 @@ func (c *Cache) SetVisitorID(ctx context.Context, hardwareHash, visitorID string
 	return nil
 }
 
+// deleteIfEquals deletes KEYS[1] only while it still holds ARGV[1].
+var deleteIfEquals = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// DeleteVisitorID removes the hardware hash mapping if it still points at
+// visitorID, and reports whether it did.
+func (c *Cache) DeleteVisitorID(ctx context.Context, hardwareHash, visitorID string) (bool, error) {
+	key := fmt.Sprintf("hw:%s", hardwareHash)
+	n, err := deleteIfEquals.Run(ctx, c.client, []string{key}, visitorID).Int()
+	if err != nil {
+		return false, fmt.Errorf("cache delete error: %w", err)
+	}
+	return n > 0, nil
+}
+
+// VisitorIDEntry returns the raw hardware hash mapping, or nil when there is
+// none.
+func (c *Cache) VisitorIDEntry(ctx context.Context, hardwareHash string) (*Entry, error) {
+	key := fmt.Sprintf("hw:%s", hardwareHash)
+
+	pipe := c.client.Pipeline()
+	get := pipe.Get(ctx, key)
+	ttl := pipe.TTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cache get error: %w", err)
+	}
+
+	return &Entry{
+		Key:       key,
+		Values:    []string{get.Val()},
+		ExpiresIn: ttl.Val(),
+	}, nil
+}
+
 // CheckRateLimit implements token bucket rate limiting.
 func (c *Cache) CheckRateLimit(ctx context.Context, identifier string, limit int, window time.Duration) (bool, error) {
 	key := fmt.Sprintf("rl:%s", identifier)
@@ -72,12 +121,217 @@ func (c *Cache) CheckRateLimit(ctx context.Context, identifier string, limit int
 	return count <= int64(limit), nil
 }
 
+// RecordInWindow adds member to a rolling window and returns how many
+// distinct members were recorded within the last window. Re-recording an
+// existing member refreshes it, so pass a unique member to count events and
+// a repeated one to count distinct values.
+func (c *Cache) RecordInWindow(ctx context.Context, key, member string, window time.Duration) (int64, error) {
+	key = fmt.Sprintf("win:%s", key)
+	now := time.Now()
+
+	pipe := c.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixMilli()), Member: member})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", fmt.Sprintf("(%d", now.Add(-window).UnixMilli()))
+	card := pipe.ZCard(ctx, key)
+	pipe.Expire(ctx, key, window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("window record error: %w", err)
+	}
+
+	return card.Val(), nil
+}
+
+// WindowEntry returns the members of a rolling window, or nil when the
+// window is empty.
+func (c *Cache) WindowEntry(ctx context.Context, key string) (*Entry, error) {
+	key = fmt.Sprintf("win:%s", key)
+
+	pipe := c.client.Pipeline()
+	members := pipe.ZRange(ctx, key, 0, -1)
+	ttl := pipe.TTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("window read error: %w", err)
+	}
+	if len(members.Val()) == 0 {
+		return nil, nil
+	}
+
+	return &Entry{
+		Key:       key,
+		Values:    members.Val(),
+		ExpiresIn: ttl.Val(),
+	}, nil
+}
+
+// DeleteWindow drops a rolling window entirely.
+func (c *Cache) DeleteWindow(ctx context.Context, key string) (bool, error) {
+	n, err := c.client.Del(ctx, fmt.Sprintf("win:%s", key)).Result()
+	if err != nil {
+		return false, fmt.Errorf("window delete error: %w", err)
+	}
+	return n > 0, nil
+}
+
+// RemoveFromWindow removes one member from a rolling window.
+func (c *Cache) RemoveFromWindow(ctx context.Context, key, member string) error {
+	if err := c.client.ZRem(ctx, fmt.Sprintf("win:%s", key), member).Err(); err != nil {
+		return fmt.Errorf("window remove error: %w", err)
+	}
+	return nil
+}
+
+// GetOrCreateSalt returns the salt stored under name, first storing
+// candidate with the given lifetime if there is none. Concurrent callers
+// all get whichever candidate was stored first.
+func (c *Cache) GetOrCreateSalt(ctx context.Context, name string, candidate []byte, ttl time.Duration) ([]byte, error) {
+	key := fmt.Sprintf("salt:%s", name)
+	if err := c.client.SetNX(ctx, key, candidate, ttl).Err(); err != nil {
+		return nil, fmt.Errorf("cache set error: %w", err)
+	}
+	salt, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("cache get error: %w", err)
+	}
+	return salt, nil
+}
+
+// GetSalts returns the salts stored under names, with nil for expired ones.
+func (c *Cache) GetSalts(ctx context.Context, names []string) ([][]byte, error) {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = fmt.Sprintf("salt:%s", name)
+	}
+
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("cache get error: %w", err)
+	}
+
+	salts := make([][]byte, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			salts[i] = []byte(s)
+		}
+	}
+	return salts, nil
+}
+
+// anonymityResize is shared by the anonymity scripts. KEYS[1] maps each
+// member to its feature vector, KEYS[2] each vector to its size and KEYS[3]
+// each size to how many members are in sets of that size. resize changes a
+// vector's size by delta and moves its members to the new histogram bucket.
+const anonymityResize = `
+local function resize(vector, delta)
+	local old = tonumber(redis.call("HGET", KEYS[2], vector)) or 0
+	local new = old + delta
+	if old > 0 and redis.call("HINCRBY", KEYS[3], old, -old) <= 0 then
+		redis.call("HDEL", KEYS[3], old)
+	end
+	if new > 0 then
+		redis.call("HSET", KEYS[2], vector, new)
+		redis.call("HINCRBY", KEYS[3], new, new)
+	else
+		redis.call("HDEL", KEYS[2], vector)
+	end
+end
+`
+
+// recordAnonymity counts member ARGV[1] under vector ARGV[2], moving it out
+// of the vector it was last counted under. Returns the vector's size and
+// the histogram.
+var recordAnonymity = redis.NewScript(anonymityResize + `
+local previous = redis.call("HGET", KEYS[1], ARGV[1])
+if previous ~= ARGV[2] then
+	if previous then
+		resize(previous, -1)
+	end
+	redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+	resize(ARGV[2], 1)
+end
+return {tonumber(redis.call("HGET", KEYS[2], ARGV[2])), redis.call("HGETALL", KEYS[3])}
+`)
+
+// forgetAnonymity removes member ARGV[1] from the vector it is counted
+// under. Returns 1 if it was counted.
+var forgetAnonymity = redis.NewScript(anonymityResize + `
+local previous = redis.call("HGET", KEYS[1], ARGV[1])
+if not previous then
+	return 0
+end
+resize(previous, -1)
+redis.call("HDEL", KEYS[1], ARGV[1])
+return 1
+`)
+
+// anonymityKeys are the keys the anonymity scripts take.
+var anonymityKeys = []string{"anon:vectors", "anon:sizes", "anon:histogram"}
+
+// RecordAnonymity counts member under vector, once, and returns how many
+// members share vector along with the population histogram: for each
+// anonymity set size, how many members are in sets of that size. A member
+// is only counted under the vector it was last recorded with. The counts
+// are kept incrementally and never expire; members should be pseudonyms,
+// not identifiers.
+func (c *Cache) RecordAnonymity(ctx context.Context, vector, member string) (int64, map[int64]int64, error) {
+	res, err := recordAnonymity.Run(ctx, c.client, anonymityKeys, member, vector).Slice()
+	if err != nil {
+		return 0, nil, fmt.Errorf("anonymity record error: %w", err)
+	}
+
+	size, _ := res[0].(int64)
+	fields, _ := res[1].([]any)
+	histogram := make(map[int64]int64, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		k, _ := fields[i].(string)
+		v, _ := fields[i+1].(string)
+		bucket, err := strconv.ParseInt(k, 10, 64)
+		if err != nil {
+			continue
+		}
+		members, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		histogram[bucket] = members
+	}
+	return size, histogram, nil
+}
+
+// ForgetAnonymity stops counting member and reports whether it was counted.
+func (c *Cache) ForgetAnonymity(ctx context.Context, member string) (bool, error) {
+	n, err := forgetAnonymity.Run(ctx, c.client, anonymityKeys, member).Int64()
+	if err != nil {
+		return false, fmt.Errorf("anonymity forget error: %w", err)
+	}
+	return n > 0, nil
+}
+
+// AnonymityEntry returns the member and the vector it is counted under, or
+// nil when it is not counted.
+func (c *Cache) AnonymityEntry(ctx context.Context, member string) (*Entry, error) {
+	vector, err := c.client.HGet(ctx, anonymityKeys[0], member).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("anonymity read error: %w", err)
+	}
+	return &Entry{Key: anonymityKeys[0], Values: []string{member, vector}}, nil
+}
+
 // IncrementMetric increments a counter metric.
 func (c *Cache) IncrementMetric(ctx context.Context, metric string) error {
 	key := fmt.Sprintf("metric:%s", metric)
 	return c.client.Incr(ctx, key).Err()
 }
 
+// AddToMetric increases a counter metric by n.
+func (c *Cache) AddToMetric(ctx context.Context, metric string, n int64) error {
+	key := fmt.Sprintf("metric:%s", metric)
+	return c.client.IncrBy(ctx, key, n).Err()
+}
+
 // GetMetric retrieves a metric value.
 func (c *Cache) GetMetric(ctx context.Context, metric string) (int64, error) {
 	key := fmt.Sprintf("metric:%s", metric)
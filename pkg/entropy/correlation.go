@@ -0,0 +1,123 @@
+package entropy
+
+import "sort"
+
+// MutualInformation returns I(X;Y) = H(X) + H(Y) - H(X,Y), the bits two
+// signals share. Rounding noise below zero is clamped.
+func MutualInformation(hx, hy, hxy float64) float64 {
+	if mi := hx + hy - hxy; mi > 0 {
+		return mi
+	}
+	return 0
+}
+
+// Redundancy scales I(X;Y) by the smaller of H(X) and H(Y): 1 when one
+// signal fully determines the other, 0 when they are independent or either
+// is constant.
+func Redundancy(hx, hy, hxy float64) float64 {
+	lower := min(hx, hy)
+	if lower <= 0 {
+		return 0
+	}
+	return min(MutualInformation(hx, hy, hxy)/lower, 1)
+}
+
+// Pair is the joint entropy and shared information of two signals.
+type Pair struct {
+	X, Y              string
+	HX, HY, HXY       float64
+	MutualInformation float64
+	Redundancy        float64
+}
+
+// Correlator accumulates the distribution of every signal, and the joint
+// distribution of every pair of signals, one population member at a time.
+// A signal a member did not report counts as one more value ("missing").
+type Correlator struct {
+	signals []string
+	single  []map[string]int64
+	joint   [][]map[[2]string]int64 // joint[i][j] for i < j
+	members int64
+}
+
+// NewCorrelator returns a Correlator over the given signals.
+func NewCorrelator(signals []string) *Correlator {
+	sorted := append([]string(nil), signals...)
+	sort.Strings(sorted)
+
+	c := &Correlator{
+		signals: sorted,
+		single:  make([]map[string]int64, len(sorted)),
+		joint:   make([][]map[[2]string]int64, len(sorted)),
+	}
+	for i := range sorted {
+		c.single[i] = make(map[string]int64)
+		c.joint[i] = make([]map[[2]string]int64, len(sorted))
+		for j := i + 1; j < len(sorted); j++ {
+			c.joint[i][j] = make(map[[2]string]int64)
+		}
+	}
+	return c
+}
+
+// Add records one member's signal values. Signals outside the Correlator's
+// set are ignored.
+func (c *Correlator) Add(values map[string]string) {
+	c.members++
+
+	row := make([]string, len(c.signals))
+	for i, signal := range c.signals {
+		row[i] = values[signal]
+		c.single[i][row[i]]++
+	}
+	for i := range row {
+		for j := i + 1; j < len(row); j++ {
+			c.joint[i][j][[2]string{row[i], row[j]}]++
+		}
+	}
+}
+
+// Members returns how many members were added.
+func (c *Correlator) Members() int64 {
+	return c.members
+}
+
+// Pairs returns every pair of signals, most redundant first.
+func (c *Correlator) Pairs() []Pair {
+	h := make([]float64, len(c.signals))
+	for i, counts := range c.single {
+		h[i] = Shannon(mapCounts(counts))
+	}
+
+	var pairs []Pair
+	for i := range c.signals {
+		for j := i + 1; j < len(c.signals); j++ {
+			hxy := Shannon(mapCounts(c.joint[i][j]))
+			pairs = append(pairs, Pair{
+				X:                 c.signals[i],
+				Y:                 c.signals[j],
+				HX:                h[i],
+				HY:                h[j],
+				HXY:               hxy,
+				MutualInformation: MutualInformation(h[i], h[j], hxy),
+				Redundancy:        Redundancy(h[i], h[j], hxy),
+			})
+		}
+	}
+
+	sort.SliceStable(pairs, func(a, b int) bool {
+		if pairs[a].Redundancy != pairs[b].Redundancy {
+			return pairs[a].Redundancy > pairs[b].Redundancy
+		}
+		return pairs[a].MutualInformation > pairs[b].MutualInformation
+	})
+	return pairs
+}
+
+func mapCounts[K comparable](m map[K]int64) []int64 {
+	counts := make([]int64, 0, len(m))
+	for _, c := range m {
+		counts = append(counts, c)
+	}
+	return counts
+}
@@ -0,0 +1,83 @@
+package entropy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMutualInformation(t *testing.T) {
+	tests := []struct {
+		name          string
+		hx, hy, hxy   float64
+		expectedMI    float64
+		expectedRatio float64
+	}{
+		{"independent", 1, 1, 2, 0, 0},
+		{"identical", 1, 1, 1, 1, 1},
+		{"one determines the other", 2, 1, 2, 1, 1},
+		{"partial", 2, 2, 3, 1, 0.5},
+		{"constant signal", 0, 2, 2, 0, 0},
+		{"rounding noise", 1, 1, 2.0000001, 0, 0},
+	}
+
+	for _, tt := range tests {
+		if got := MutualInformation(tt.hx, tt.hy, tt.hxy); math.Abs(got-tt.expectedMI) > 1e-9 {
+			t.Errorf("%s: MutualInformation() = %.4f, want %.4f", tt.name, got, tt.expectedMI)
+		}
+		if got := Redundancy(tt.hx, tt.hy, tt.hxy); math.Abs(got-tt.expectedRatio) > 1e-9 {
+			t.Errorf("%s: Redundancy() = %.4f, want %.4f", tt.name, got, tt.expectedRatio)
+		}
+	}
+}
+
+func TestCorrelator(t *testing.T) {
+	c := NewCorrelator([]string{"webgl", "platform", "tz"})
+
+	// platform is fully determined by webgl; tz is independent of both.
+	members := []map[string]string{
+		{"webgl": "apple_m1", "platform": "MacIntel", "tz": "Europe/Berlin"},
+		{"webgl": "apple_m1", "platform": "MacIntel", "tz": "America/New_York"},
+		{"webgl": "nvidia", "platform": "Win32", "tz": "Europe/Berlin"},
+		{"webgl": "nvidia", "platform": "Win32", "tz": "America/New_York"},
+	}
+	for _, m := range members {
+		c.Add(m)
+	}
+
+	if c.Members() != 4 {
+		t.Fatalf("Members() = %d, want 4", c.Members())
+	}
+
+	pairs := c.Pairs()
+	if len(pairs) != 3 {
+		t.Fatalf("Pairs() returned %d pairs, want 3", len(pairs))
+	}
+
+	top := pairs[0]
+	if top.X != "platform" || top.Y != "webgl" {
+		t.Errorf("Most redundant pair = %s × %s, want platform × webgl", top.X, top.Y)
+	}
+	if math.Abs(top.HXY-1) > 1e-9 || math.Abs(top.MutualInformation-1) > 1e-9 || top.Redundancy != 1 {
+		t.Errorf("platform × webgl = %+v, want H(X,Y) 1, I 1, redundancy 1", top)
+	}
+	for _, p := range pairs[1:] {
+		if p.MutualInformation > 1e-9 {
+			t.Errorf("%s × %s shares %.4f bits, want 0", p.X, p.Y, p.MutualInformation)
+		}
+	}
+}
+
+func TestCorrelator_MissingValues(t *testing.T) {
+	c := NewCorrelator([]string{"canvas", "audio"})
+	c.Add(map[string]string{"canvas": "a"})
+	c.Add(map[string]string{"canvas": "b", "audio": "x"})
+
+	// Missing audio is a value of its own, so audio still separates the two.
+	p := c.Pairs()[0]
+	if p.X != "audio" || math.Abs(p.HX-1) > 1e-9 {
+		t.Errorf("Missing values were not counted: %+v", p)
+	}
+	if p.Redundancy != 1 {
+		t.Errorf("Redundancy = %.4f, want 1", p.Redundancy)
+	}
+}
@@ -0,0 +1,72 @@
+// Package entropy measures how much identifying information signals carry
+// across a population of visitors.
+package entropy
+
+import "math"
+
+// Shannon returns the entropy H(X) = -Σ p(x) log₂ p(x), in bits, of the
+// distribution with the given frequency counts. Non-positive counts are
+// ignored.
+func Shannon(counts []int64) float64 {
+	var total int64
+	for _, c := range counts {
+		if c > 0 {
+			total += c
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	h := 0.0
+	for _, c := range counts {
+		if c <= 0 {
+			continue
+		}
+		p := float64(c) / float64(total)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// Normalized scales h by log₂ n, the most entropy a population of n can
+// show, so results compare across population sizes. It is 0 for n < 2.
+func Normalized(h float64, n int64) float64 {
+	if n < 2 {
+		return 0
+	}
+	return h / math.Log2(float64(n))
+}
+
+// WithMissing returns counts extended by the members of a population of n
+// not covered by them, which count as one more value ("not reported").
+func WithMissing(counts []int64, n int64) []int64 {
+	var covered int64
+	for _, c := range counts {
+		covered += c
+	}
+	if covered >= n {
+		return counts
+	}
+	return append(append([]int64(nil), counts...), n-covered)
+}
+
+// MoreUniqueThan returns the percentage of a population that shares its
+// feature vector with more members than size. histogram maps each
+// anonymity set size to how many members are in sets of that size.
+func MoreUniqueThan(histogram map[int64]int64, size int64) float64 {
+	var total, larger int64
+	for k, members := range histogram {
+		if members <= 0 {
+			continue
+		}
+		total += members
+		if k > size {
+			larger += members
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(larger) / float64(total)
+}
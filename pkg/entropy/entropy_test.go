@@ -0,0 +1,76 @@
+package entropy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestShannon(t *testing.T) {
+	tests := []struct {
+		name     string
+		counts   []int64
+		expected float64
+	}{
+		{"empty", nil, 0},
+		{"single value", []int64{42}, 0},
+		{"fair coin", []int64{5, 5}, 1},
+		{"uniform over 8", []int64{1, 1, 1, 1, 1, 1, 1, 1}, 3},
+		{"skewed", []int64{3, 1}, 0.8113},
+		{"ignores non-positive", []int64{2, 0, -1, 2}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := Shannon(tt.counts); math.Abs(got-tt.expected) > 1e-4 {
+			t.Errorf("%s: Shannon() = %.4f, want %.4f", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalized(t *testing.T) {
+	if got := Normalized(3, 8); got != 1 {
+		t.Errorf("Normalized(3, 8) = %.4f, want 1", got)
+	}
+	if got := Normalized(1, 16); got != 0.25 {
+		t.Errorf("Normalized(1, 16) = %.4f, want 0.25", got)
+	}
+	if got := Normalized(0, 1); got != 0 {
+		t.Errorf("Normalized(0, 1) = %.4f, want 0", got)
+	}
+}
+
+func TestWithMissing(t *testing.T) {
+	counts := []int64{3, 3}
+	if got := WithMissing(counts, 8); len(got) != 3 || got[2] != 2 {
+		t.Errorf("WithMissing() = %v, want [3 3 2]", got)
+	}
+	if got := WithMissing(counts, 6); len(got) != 2 {
+		t.Errorf("WithMissing() = %v, want counts unchanged", got)
+	}
+	if len(counts) != 2 {
+		t.Error("WithMissing() modified its input")
+	}
+}
+
+func TestMoreUniqueThan(t *testing.T) {
+	// Three unique visitors, one pair and one set of five: ten in total.
+	histogram := map[int64]int64{1: 3, 2: 2, 5: 5}
+
+	tests := []struct {
+		name      string
+		histogram map[int64]int64
+		size      int64
+		expected  float64
+	}{
+		{"unique", histogram, 1, 70},
+		{"pair", histogram, 2, 50},
+		{"largest set", histogram, 5, 0},
+		{"ignores empty sizes", map[int64]int64{1: 1, 3: 0, 4: 4}, 1, 80},
+		{"empty population", nil, 1, 0},
+	}
+
+	for _, tt := range tests {
+		if got := MoreUniqueThan(tt.histogram, tt.size); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("%s: MoreUniqueThan() = %.2f, want %.2f", tt.name, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package envelope encrypts records at rest. Each record is encrypted with
+// its own random data key, and the data key is wrapped by a master key
+// identified by ID, so master keys can be rotated by rewrapping data keys
+// without touching the records.
+package envelope
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrUnknownKey is returned for records wrapped by a master key that is not
+// in the keyring.
+var ErrUnknownKey = errors.New("envelope: unknown master key")
+
+// MasterKey is an AES-256 key that wraps data keys.
+type MasterKey struct {
+	ID  string
+	key []byte
+}
+
+// NewMasterKey returns a master key from 32 raw bytes.
+func NewMasterKey(id string, key []byte) (MasterKey, error) {
+	if id == "" {
+		return MasterKey{}, errors.New("envelope: master key ID is required")
+	}
+	if len(key) != 32 {
+		return MasterKey{}, fmt.Errorf("envelope: master key %q must be 32 bytes", id)
+	}
+	return MasterKey{ID: id, key: key}, nil
+}
+
+// ParseMasterKey parses a master key spec of the form "id:base64".
+func ParseMasterKey(spec string) (MasterKey, error) {
+	id, encoded, ok := strings.Cut(spec, ":")
+	if !ok || id == "" {
+		return MasterKey{}, errors.New("envelope: master key spec must be id:base64")
+	}
+
+	material, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return MasterKey{}, fmt.Errorf("envelope: master key %q is not valid base64", id)
+	}
+	return NewMasterKey(id, material)
+}
+
+// Sealed is an encrypted record: the ciphertext, its data key wrapped by the
+// master key KeyID.
+type Sealed struct {
+	KeyID      string
+	DataKey    []byte
+	Ciphertext []byte
+}
+
+// Keyring holds the master keys able to unwrap stored data keys and the
+// active one used to wrap new ones.
+type Keyring struct {
+	keys   map[string]MasterKey
+	active MasterKey
+}
+
+// NewKeyring returns a keyring that wraps with the key activeID.
+func NewKeyring(keys []MasterKey, activeID string) (*Keyring, error) {
+	k := &Keyring{keys: make(map[string]MasterKey, len(keys))}
+	for _, key := range keys {
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("envelope: duplicate master key %q", key.ID)
+		}
+		k.keys[key.ID] = key
+	}
+
+	active, ok := k.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("envelope: active master key %q is not configured", activeID)
+	}
+	k.active = active
+	return k, nil
+}
+
+// LoadKeyring builds a keyring from "id:base64" specs and, when keyFile is
+// set, a file holding one spec per line. Blank lines and lines starting
+// with # are skipped.
+func LoadKeyring(specs []string, keyFile, activeID string) (*Keyring, error) {
+	specs = append([]string(nil), specs...)
+	if keyFile != "" {
+		fileSpecs, err := readKeyFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, fileSpecs...)
+	}
+
+	keys := make([]MasterKey, 0, len(specs))
+	for _, spec := range specs {
+		key, err := ParseMasterKey(spec)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return NewKeyring(keys, activeID)
+}
+
+func readKeyFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: failed to open key file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var specs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		specs = append(specs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("envelope: failed to read key file: %w", err)
+	}
+	return specs, nil
+}
+
+// ActiveKeyID returns the ID of the master key new data keys are wrapped by.
+func (k *Keyring) ActiveKeyID() string {
+	return k.active.ID
+}
+
+// Seal encrypts plaintext under a fresh data key. aad binds the ciphertext
+// to its record, e.g. a row ID; the same aad must be passed to Open.
+func (k *Keyring) Seal(plaintext, aad []byte) (Sealed, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Sealed{}, fmt.Errorf("envelope: failed to generate data key: %w", err)
+	}
+
+	ciphertext, err := encrypt(dataKey, plaintext, aad)
+	if err != nil {
+		return Sealed{}, err
+	}
+	wrapped, err := encrypt(k.active.key, dataKey, []byte(k.active.ID))
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	return Sealed{KeyID: k.active.ID, DataKey: wrapped, Ciphertext: ciphertext}, nil
+}
+
+// Open decrypts a sealed record.
+func (k *Keyring) Open(s Sealed, aad []byte) ([]byte, error) {
+	dataKey, err := k.unwrap(s)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(dataKey, s.Ciphertext, aad)
+}
+
+// Rewrap wraps a record's data key with the active master key. The
+// ciphertext is unchanged.
+func (k *Keyring) Rewrap(s Sealed) (Sealed, error) {
+	if s.KeyID == k.active.ID {
+		return s, nil
+	}
+
+	dataKey, err := k.unwrap(s)
+	if err != nil {
+		return Sealed{}, err
+	}
+	wrapped, err := encrypt(k.active.key, dataKey, []byte(k.active.ID))
+	if err != nil {
+		return Sealed{}, err
+	}
+
+	return Sealed{KeyID: k.active.ID, DataKey: wrapped, Ciphertext: s.Ciphertext}, nil
+}
+
+func (k *Keyring) unwrap(s Sealed) ([]byte, error) {
+	master, ok := k.keys[s.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, s.KeyID)
+	}
+	return decrypt(master.key, s.DataKey, []byte(master.ID))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns nonce || AES-256-GCM ciphertext.
+func encrypt(key, plaintext, aad []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("envelope: failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func decrypt(key, ciphertext, aad []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errors.New("envelope: ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, aad)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,108 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(t *testing.T, id string, b byte) MasterKey {
+	t.Helper()
+	key, err := NewMasterKey(id, bytes.Repeat([]byte{b}, 32))
+	if err != nil {
+		t.Fatalf("NewMasterKey() failed: %v", err)
+	}
+	return key
+}
+
+func TestSealAndOpen(t *testing.T) {
+	keyring, err := NewKeyring([]MasterKey{testKey(t, "k1", 1)}, "k1")
+	if err != nil {
+		t.Fatalf("NewKeyring() failed: %v", err)
+	}
+
+	plaintext := []byte(`{"timezone":"Europe/Berlin"}`)
+	sealed, err := keyring.Seal(plaintext, []byte("row-1"))
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+	if sealed.KeyID != "k1" || bytes.Contains(sealed.Ciphertext, plaintext) {
+		t.Fatalf("Seal() returned %+v", sealed)
+	}
+
+	got, err := keyring.Open(sealed, []byte("row-1"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("Open() = %s, want %s", got, plaintext)
+	}
+
+	if _, err := keyring.Open(sealed, []byte("row-2")); err == nil {
+		t.Error("Expected error opening with another row's aad")
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	old, _ := NewKeyring([]MasterKey{testKey(t, "k1", 1)}, "k1")
+	sealed, err := old.Seal([]byte("signals"), nil)
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+
+	rotated, _ := NewKeyring([]MasterKey{testKey(t, "k1", 1), testKey(t, "k2", 2)}, "k2")
+	rewrapped, err := rotated.Rewrap(sealed)
+	if err != nil {
+		t.Fatalf("Rewrap() failed: %v", err)
+	}
+	if rewrapped.KeyID != "k2" || !bytes.Equal(rewrapped.Ciphertext, sealed.Ciphertext) {
+		t.Errorf("Rewrap() returned %+v", rewrapped)
+	}
+
+	retired, _ := NewKeyring([]MasterKey{testKey(t, "k2", 2)}, "k2")
+	got, err := retired.Open(rewrapped, nil)
+	if err != nil || string(got) != "signals" {
+		t.Errorf("Open() after rotation = %q, %v", got, err)
+	}
+	if _, err := retired.Open(sealed, nil); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey for retired key, got %v", err)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	spec := func(id string, b byte) string {
+		return id + ":" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+	}
+
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte("# retired\n"+spec("k1", 1)+"\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keyring, err := LoadKeyring([]string{spec("k2", 2)}, path, "k2")
+	if err != nil {
+		t.Fatalf("LoadKeyring() failed: %v", err)
+	}
+	if keyring.ActiveKeyID() != "k2" || len(keyring.keys) != 2 {
+		t.Errorf("LoadKeyring() loaded %d keys, active %q", len(keyring.keys), keyring.ActiveKeyID())
+	}
+
+	invalid := []struct {
+		name     string
+		specs    []string
+		activeID string
+	}{
+		{"missing active key", []string{spec("k1", 1)}, "k2"},
+		{"duplicate key", []string{spec("k1", 1), spec("k1", 2)}, "k1"},
+		{"short key", []string{"k1:" + base64.StdEncoding.EncodeToString([]byte("short"))}, "k1"},
+		{"missing ID", []string{spec("", 1)}, ""},
+	}
+	for _, tt := range invalid {
+		if _, err := LoadKeyring(tt.specs, "", tt.activeID); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package geoip resolves client IPs to a coarse location and network owner
+// from local MaxMind-format (.mmdb) databases, such as GeoLite2-City and
+// GeoLite2-ASN. Only country, region, city and ASN are returned.
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+)
+
+// cityRecord is the subset of a City or Country database record we keep.
+type cityRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+type asnRecord struct {
+	Number       uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// Reader looks addresses up in a location database, an ASN database, or
+// both. Either path may be empty.
+type Reader struct {
+	cityPath string
+	asnPath  string
+
+	mu   sync.RWMutex
+	city *maxminddb.Reader
+	asn  *maxminddb.Reader
+}
+
+// Open opens the configured databases.
+func Open(cityPath, asnPath string) (*Reader, error) {
+	if cityPath == "" && asnPath == "" {
+		return nil, errors.New("no GeoIP database configured")
+	}
+
+	r := &Reader{cityPath: cityPath, asnPath: asnPath}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reopens the databases, picking up updated files. On error the
+// previously opened databases stay in use.
+func (r *Reader) Reload() error {
+	city, err := openOptional(r.cityPath)
+	if err != nil {
+		return err
+	}
+	asn, err := openOptional(r.asnPath)
+	if err != nil {
+		if city != nil {
+			_ = city.Close()
+		}
+		return err
+	}
+
+	r.mu.Lock()
+	oldCity, oldASN := r.city, r.asn
+	r.city, r.asn = city, asn
+	r.mu.Unlock()
+
+	// Lookups hold the read lock, so nothing uses the old readers now.
+	return errors.Join(closeOptional(oldCity), closeOptional(oldASN))
+}
+
+// Lookup returns the location of addr, or nil when neither database has it.
+func (r *Reader) Lookup(addr netip.Addr) *models.GeoLocation {
+	if !addr.IsValid() {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var loc models.GeoLocation
+
+	if r.city != nil {
+		var rec cityRecord
+		if err := r.city.Lookup(addr).Decode(&rec); err == nil {
+			loc.Country = rec.Country.ISOCode
+			if len(rec.Subdivisions) > 0 {
+				loc.Region = rec.Subdivisions[0].ISOCode
+			}
+			loc.City = rec.City.Names["en"]
+		}
+	}
+
+	if r.asn != nil {
+		var rec asnRecord
+		if err := r.asn.Lookup(addr).Decode(&rec); err == nil {
+			loc.ASN = rec.Number
+			loc.ASOrg = rec.Organization
+		}
+	}
+
+	if loc == (models.GeoLocation{}) {
+		return nil
+	}
+	return &loc
+}
+
+// Close releases the databases.
+func (r *Reader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := errors.Join(closeOptional(r.city), closeOptional(r.asn))
+	r.city, r.asn = nil, nil
+	return err
+}
+
+// Stats reports which databases are loaded and their build epochs.
+func (r *Reader) Stats() map[string]int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := make(map[string]int)
+	if r.city != nil {
+		stats["city_build_epoch"] = int(r.city.Metadata.BuildEpoch)
+	}
+	if r.asn != nil {
+		stats["asn_build_epoch"] = int(r.asn.Metadata.BuildEpoch)
+	}
+	return stats
+}
+
+func openOptional(path string) (*maxminddb.Reader, error) {
+	if path == "" {
+		return nil, nil
+	}
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+func closeOptional(db *maxminddb.Reader) error {
+	if db == nil {
+		return nil
+	}
+	return db.Close()
+}
@@ -0,0 +1,297 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+)
+
+func TestOpen_RequiresADatabase(t *testing.T) {
+	if _, err := Open("", ""); err == nil {
+		t.Error("Expected an error when no database is configured")
+	}
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.mmdb"), ""); err == nil {
+		t.Error("Expected an error for a missing database file")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	cityPath := writeMMDB(t, dir, "city.mmdb", "81.2.69.0/24", cityData("GB", "ENG", "London"), 100)
+	asnPath := writeMMDB(t, dir, "asn.mmdb", "81.2.0.0/16", asnData(20712, "Andrews & Arnold Ltd"), 200)
+
+	r, err := Open(cityPath, asnPath)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	tests := []struct {
+		addr     string
+		expected *models.GeoLocation
+	}{
+		{"81.2.69.142", &models.GeoLocation{Country: "GB", Region: "ENG", City: "London", ASN: 20712, ASOrg: "Andrews & Arnold Ltd"}},
+		{"81.2.70.1", &models.GeoLocation{ASN: 20712, ASOrg: "Andrews & Arnold Ltd"}},
+		{"203.0.113.1", nil},
+	}
+
+	for _, tt := range tests {
+		got := r.Lookup(netip.MustParseAddr(tt.addr))
+		if !equalLocation(got, tt.expected) {
+			t.Errorf("Lookup(%s) = %+v, want %+v", tt.addr, got, tt.expected)
+		}
+	}
+
+	if got := r.Lookup(netip.Addr{}); got != nil {
+		t.Errorf("Lookup(invalid) = %+v, want nil", got)
+	}
+}
+
+func TestLookup_SingleDatabase(t *testing.T) {
+	asnPath := writeMMDB(t, t.TempDir(), "asn.mmdb", "81.2.0.0/16", asnData(20712, "Andrews & Arnold Ltd"), 200)
+
+	r, err := Open("", asnPath)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	got := r.Lookup(netip.MustParseAddr("81.2.69.142"))
+	if got == nil || got.ASN != 20712 || got.Country != "" {
+		t.Errorf("Lookup() = %+v, want ASN 20712 and no location", got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	dir := t.TempDir()
+	cityPath := writeMMDB(t, dir, "city.mmdb", "81.2.69.0/24", cityData("GB", "ENG", "London"), 100)
+
+	r, err := Open(cityPath, "")
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+
+	stats := r.Stats()
+	if len(stats) != 1 || stats["city_build_epoch"] != 100 {
+		t.Errorf("Stats() = %v, want only city_build_epoch 100", stats)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	if stats := r.Stats(); len(stats) != 0 {
+		t.Errorf("Stats() after Close = %v, want empty", stats)
+	}
+}
+
+func TestLookup_AfterClose(t *testing.T) {
+	cityPath := writeMMDB(t, t.TempDir(), "city.mmdb", "81.2.69.0/24", cityData("GB", "ENG", "London"), 100)
+
+	r, err := Open(cityPath, "")
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	_ = r.Close()
+
+	if got := r.Lookup(netip.MustParseAddr("81.2.69.142")); got != nil {
+		t.Errorf("Lookup() after Close = %+v, want nil", got)
+	}
+
+	// A zero Reader has no databases open and must not panic either.
+	var zero Reader
+	if got := zero.Lookup(netip.MustParseAddr("81.2.69.142")); got != nil {
+		t.Errorf("Lookup() on a zero Reader = %+v, want nil", got)
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	cityPath := writeMMDB(t, dir, "city.mmdb", "81.2.69.0/24", cityData("GB", "ENG", "London"), 100)
+
+	r, err := Open(cityPath, "")
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	addr := netip.MustParseAddr("81.2.69.142")
+
+	// Lookups racing with reloads must always see a whole database.
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if got := r.Lookup(addr); got == nil || (got.City != "London" && got.City != "Manchester") {
+					t.Errorf("Lookup() during reload = %+v", got)
+					return
+				}
+			}
+		})
+	}
+
+	writeMMDB(t, dir, "city.mmdb", "81.2.69.0/24", cityData("GB", "ENG", "Manchester"), 300)
+	for range 20 {
+		if err := r.Reload(); err != nil {
+			t.Errorf("Reload() failed: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if got := r.Lookup(addr); got == nil || got.City != "Manchester" {
+		t.Errorf("Lookup() after Reload = %+v, want Manchester", got)
+	}
+	if stats := r.Stats(); stats["city_build_epoch"] != 300 {
+		t.Errorf("Stats() after Reload = %v, want city_build_epoch 300", stats)
+	}
+
+	// A failed reload keeps the previously opened database.
+	if err := os.Remove(cityPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Error("Expected Reload() to fail for a missing file")
+	}
+	if got := r.Lookup(addr); got == nil || got.City != "Manchester" {
+		t.Errorf("Lookup() after failed Reload = %+v, want Manchester", got)
+	}
+}
+
+func equalLocation(a, b *models.GeoLocation) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func cityData(country, region, city string) []byte {
+	return mmdbMap(
+		"country", mmdbMap("iso_code", mmdbString(country)),
+		"subdivisions", mmdbArray(mmdbMap("iso_code", mmdbString(region))),
+		"city", mmdbMap("names", mmdbMap("en", mmdbString(city))),
+	)
+}
+
+func asnData(number uint32, org string) []byte {
+	return mmdbMap(
+		"autonomous_system_number", mmdbUint32(number),
+		"autonomous_system_organization", mmdbString(org),
+	)
+}
+
+// writeMMDB writes a minimal IPv4 MaxMind DB in which network maps to the
+// encoded record and every other address is absent.
+func writeMMDB(t *testing.T, dir, name, network string, record []byte, buildEpoch uint32) string {
+	t.Helper()
+
+	prefix := netip.MustParsePrefix(network)
+	ip := prefix.Addr().As4()
+	nodeCount := uint32(prefix.Bits())
+
+	// One node per prefix bit; the path leads to the record, the other
+	// branches to "no data" (nodeCount). Records are 24 bits.
+	var tree []byte
+	for i := range nodeCount {
+		next := i + 1
+		if next == nodeCount {
+			next = nodeCount + 16 // Data section offset 0
+		}
+		records := [2]uint32{nodeCount, nodeCount}
+		records[ip[i/8]>>(7-i%8)&1] = next
+		for _, rec := range records {
+			tree = append(tree, byte(rec>>16), byte(rec>>8), byte(rec))
+		}
+	}
+
+	var db []byte
+	db = append(db, tree...)
+	db = append(db, make([]byte, 16)...)
+	db = append(db, record...)
+	db = append(db, "\xAB\xCD\xEFMaxMind.com"...)
+	db = append(db, mmdbMap(
+		"node_count", mmdbUint32(nodeCount),
+		"record_size", mmdbUint16(24),
+		"ip_version", mmdbUint16(4),
+		"database_type", mmdbString("Signet-Test"),
+		"languages", mmdbArray(mmdbString("en")),
+		"binary_format_major_version", mmdbUint16(2),
+		"binary_format_minor_version", mmdbUint16(0),
+		"build_epoch", mmdbUint64(uint64(buildEpoch)),
+		"description", mmdbMap("en", mmdbString("Signet test database")),
+	)...)
+
+	path := filepath.Join(dir, name)
+	// Replace rather than overwrite, as an open reader may have the file mapped.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, db, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// MaxMind DB data section encoders for the few types the fixtures use.
+
+func mmdbControl(typ, size int) []byte {
+	var sizeBytes []byte
+	switch {
+	case size < 29:
+	case size < 29+256:
+		sizeBytes = []byte{byte(size - 29)}
+		size = 29
+	default:
+		panic("mmdb fixture value too large")
+	}
+
+	if typ <= 7 {
+		return append([]byte{byte(typ<<5 | size)}, sizeBytes...)
+	}
+	return append([]byte{byte(size), byte(typ - 7)}, sizeBytes...)
+}
+
+func mmdbString(s string) []byte {
+	return append(mmdbControl(2, len(s)), s...)
+}
+
+func mmdbUint16(v uint16) []byte {
+	return append(mmdbControl(5, 2), byte(v>>8), byte(v))
+}
+
+func mmdbUint32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(mmdbControl(6, 4), v)
+}
+
+func mmdbUint64(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(mmdbControl(9, 8), v)
+}
+
+func mmdbArray(items ...[]byte) []byte {
+	out := mmdbControl(11, len(items))
+	for _, item := range items {
+		out = append(out, item...)
+	}
+	return out
+}
+
+// mmdbMap takes alternating keys and encoded values.
+func mmdbMap(pairs ...any) []byte {
+	out := mmdbControl(7, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		out = append(out, mmdbString(pairs[i].(string))...)
+		out = append(out, pairs[i+1].([]byte)...)
+	}
+	return out
+}
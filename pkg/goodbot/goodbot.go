@@ -0,0 +1,145 @@
+// Package goodbot tells legitimate crawlers apart from impostors by matching
+// the claimed User-Agent against the crawler operator's published IP ranges.
+// Ranges are read from local files; nothing is looked up at request time.
+package goodbot
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/iamgideonidoko/signet/pkg/iprange"
+)
+
+// Crawler is a known crawler and the User-Agent tokens it identifies with.
+type Crawler struct {
+	Name     string
+	UATokens []string
+}
+
+// KnownCrawlers lists crawlers whose operators publish IP ranges. A range
+// file named after any other crawler is matched by its file name alone.
+var KnownCrawlers = []Crawler{
+	{Name: "googlebot", UATokens: []string{"googlebot", "google-inspectiontool", "googleother", "adsbot-google", "mediapartners-google", "storebot-google"}},
+	{Name: "bingbot", UATokens: []string{"bingbot", "adidxbot", "bingpreview"}},
+	{Name: "applebot", UATokens: []string{"applebot"}},
+	{Name: "duckduckbot", UATokens: []string{"duckduckbot"}},
+	{Name: "gptbot", UATokens: []string{"gptbot", "oai-searchbot", "chatgpt-user"}},
+	{Name: "facebookbot", UATokens: []string{"facebookexternalhit", "facebookbot", "meta-externalagent"}},
+}
+
+// Result describes a request's crawler claim.
+type Result struct {
+	Crawler  string // Claimed crawler name, empty when none is claimed
+	Verified bool   // The request came from the crawler's published ranges
+}
+
+// Verifier holds crawler ranges loaded from a directory containing one
+// "<crawler>.json" or "<crawler>.txt" file per crawler.
+type Verifier struct {
+	dir string
+
+	mu       sync.RWMutex
+	crawlers []Crawler
+	ranges   map[string]*iprange.Set
+}
+
+// NewVerifier loads the range files in dir.
+func NewVerifier(dir string) (*Verifier, error) {
+	v := &Verifier{dir: dir}
+	if err := v.Reload(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Reload re-reads the range files. On error the previous ranges are kept.
+func (v *Verifier) Reload() error {
+	entries, err := os.ReadDir(v.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read good bot ranges: %w", err)
+	}
+
+	ranges := make(map[string]*iprange.Set)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".txt") {
+			continue
+		}
+
+		prefixes, err := iprange.LoadFile(filepath.Join(v.dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		name := strings.ToLower(strings.TrimSuffix(entry.Name(), ext))
+		set, ok := ranges[name]
+		if !ok {
+			set = iprange.NewSet()
+			ranges[name] = set
+		}
+		for _, p := range prefixes {
+			set.Insert(p)
+		}
+	}
+
+	crawlers := append([]Crawler(nil), KnownCrawlers...)
+	for name := range ranges {
+		if !isKnown(name) {
+			crawlers = append(crawlers, Crawler{Name: name, UATokens: []string{name}})
+		}
+	}
+
+	v.mu.Lock()
+	v.crawlers = crawlers
+	v.ranges = ranges
+	v.mu.Unlock()
+	return nil
+}
+
+// Classify checks whether the User-Agent claims a crawler and, if so,
+// whether addr lies in that crawler's ranges. Claims to be a crawler without
+// a range file cannot be judged and are ignored.
+func (v *Verifier) Classify(userAgent string, addr netip.Addr) Result {
+	ua := strings.ToLower(userAgent)
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	for _, crawler := range v.crawlers {
+		set, ok := v.ranges[crawler.Name]
+		if !ok {
+			continue
+		}
+		for _, token := range crawler.UATokens {
+			if strings.Contains(ua, token) {
+				return Result{Crawler: crawler.Name, Verified: set.Contains(addr)}
+			}
+		}
+	}
+	return Result{}
+}
+
+// Stats returns the number of prefixes loaded per crawler.
+func (v *Verifier) Stats() map[string]int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	stats := make(map[string]int, len(v.ranges))
+	for name, set := range v.ranges {
+		stats[name] = set.Len()
+	}
+	return stats
+}
+
+func isKnown(name string) bool {
+	for _, c := range KnownCrawlers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
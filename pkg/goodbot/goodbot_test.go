@@ -0,0 +1,61 @@
+package goodbot
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const googlebotUA = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+
+func TestClassify(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "googlebot.json"),
+		[]byte(`{"prefixes":[{"ipv4Prefix":"66.249.64.0/27"}]}`), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "examplebot.txt"), []byte("192.0.2.0/24\n"), 0o600)
+
+	v, err := NewVerifier(dir)
+	if err != nil {
+		t.Fatalf("NewVerifier() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		ua       string
+		addr     string
+		expected Result
+	}{
+		{"verified googlebot", googlebotUA, "66.249.64.10", Result{Crawler: "googlebot", Verified: true}},
+		{"googlebot impostor", googlebotUA, "203.0.113.5", Result{Crawler: "googlebot"}},
+		{"bingbot without ranges", "Mozilla/5.0 (compatible; bingbot/2.0)", "40.77.167.1", Result{}},
+		{"custom crawler", "ExampleBot/1.0", "192.0.2.9", Result{Crawler: "examplebot", Verified: true}},
+		{"regular browser", "Mozilla/5.0 (Windows NT 10.0) Chrome/120.0", "66.249.64.10", Result{}},
+	}
+
+	for _, tt := range tests {
+		if got := v.Classify(tt.ua, netip.MustParseAddr(tt.addr)); got != tt.expected {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestReload_KeepsRangesOnError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "googlebot.txt")
+	_ = os.WriteFile(path, []byte("66.249.64.0/27\n"), 0o600)
+
+	v, err := NewVerifier(dir)
+	if err != nil {
+		t.Fatalf("NewVerifier() failed: %v", err)
+	}
+
+	_ = os.WriteFile(path, []byte("garbage\n"), 0o600)
+	if err := v.Reload(); err == nil {
+		t.Fatal("Expected Reload() to fail on invalid file")
+	}
+
+	if !v.Classify(googlebotUA, netip.MustParseAddr("66.249.64.1")).Verified {
+		t.Error("Expected previous ranges to remain after failed reload")
+	}
+}
@@ -0,0 +1,134 @@
+// Package ipintel flags Tor exit nodes, VPN and proxy endpoints and
+// datacenter addresses from locally maintained range files.
+package ipintel
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/iamgideonidoko/signet/pkg/iprange"
+)
+
+// Flags reported by Lookup.
+const (
+	FlagTor        = "tor_exit"
+	FlagVPN        = "vpn"
+	FlagProxy      = "proxy"
+	FlagDatacenter = "datacenter"
+)
+
+// flagOrder is the order flags are reported in.
+var flagOrder = []string{FlagTor, FlagVPN, FlagProxy, FlagDatacenter}
+
+// filePrefixes maps range file name prefixes to the flag they set, so
+// "datacenter-aws.json" and "hosting.txt" both mark datacenter ranges.
+var filePrefixes = map[string]string{
+	"tor":        FlagTor,
+	"vpn":        FlagVPN,
+	"proxy":      FlagProxy,
+	"datacenter": FlagDatacenter,
+	"hosting":    FlagDatacenter,
+}
+
+// Database holds the range sets loaded from a directory of ".json" or ".txt"
+// files named "<category>[-source].<ext>".
+type Database struct {
+	dir string
+
+	mu   sync.RWMutex
+	sets map[string]*iprange.Set
+}
+
+// NewDatabase loads the range files in dir.
+func NewDatabase(dir string) (*Database, error) {
+	d := &Database{dir: dir}
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reload re-reads the range files. On error the previous ranges are kept.
+func (d *Database) Reload() error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read IP intelligence dir: %w", err)
+	}
+
+	sets := make(map[string]*iprange.Set)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".txt") {
+			continue
+		}
+
+		flag, err := fileFlag(entry.Name())
+		if err != nil {
+			return err
+		}
+
+		prefixes, err := iprange.LoadFile(filepath.Join(d.dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		set, ok := sets[flag]
+		if !ok {
+			set = iprange.NewSet()
+			sets[flag] = set
+		}
+		for _, p := range prefixes {
+			set.Insert(p)
+		}
+	}
+
+	d.mu.Lock()
+	d.sets = sets
+	d.mu.Unlock()
+	return nil
+}
+
+// Lookup returns the flags whose ranges contain addr.
+func (d *Database) Lookup(addr netip.Addr) []string {
+	if !addr.IsValid() {
+		return nil
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var flags []string
+	for _, flag := range flagOrder {
+		if set, ok := d.sets[flag]; ok && set.Contains(addr) {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// Stats returns the number of prefixes loaded per flag.
+func (d *Database) Stats() map[string]int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	stats := make(map[string]int, len(d.sets))
+	for flag, set := range d.sets {
+		stats[flag] = set.Len()
+	}
+	return stats
+}
+
+func fileFlag(name string) (string, error) {
+	base := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+	category, _, _ := strings.Cut(base, "-")
+
+	flag, ok := filePrefixes[category]
+	if !ok {
+		return "", fmt.Errorf("unrecognized IP intelligence file %q", name)
+	}
+	return flag, nil
+}
@@ -0,0 +1,52 @@
+package ipintel
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "tor.txt"), []byte("# exit nodes\n185.220.101.4\n"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "datacenter-example.txt"), []byte("185.220.100.0/22\n2001:db8::/32\n"), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "vpn.txt"), []byte("198.51.100.0/24\n"), 0o600)
+
+	d, err := NewDatabase(dir)
+	if err != nil {
+		t.Fatalf("NewDatabase() failed: %v", err)
+	}
+
+	tests := []struct {
+		addr     string
+		expected []string
+	}{
+		{"185.220.101.4", []string{FlagTor, FlagDatacenter}},
+		{"185.220.101.5", []string{FlagDatacenter}},
+		{"198.51.100.77", []string{FlagVPN}},
+		{"::ffff:198.51.100.77", []string{FlagVPN}},
+		{"2001:db8::1", []string{FlagDatacenter}},
+		{"203.0.113.1", nil},
+	}
+
+	for _, tt := range tests {
+		if got := d.Lookup(netip.MustParseAddr(tt.addr)); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Lookup(%s) = %v, want %v", tt.addr, got, tt.expected)
+		}
+	}
+
+	if got := d.Lookup(netip.Addr{}); got != nil {
+		t.Errorf("Lookup(invalid) = %v, want nil", got)
+	}
+}
+
+func TestReload_RejectsUnknownFiles(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "residential.txt"), []byte("192.0.2.0/24\n"), 0o600)
+
+	if _, err := NewDatabase(dir); err == nil {
+		t.Error("Expected an error for an unrecognized file name")
+	}
+}
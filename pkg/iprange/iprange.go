@@ -0,0 +1,172 @@
+// Package iprange provides a compact prefix set for IPv4 and IPv6 addresses
+// and loaders for the range files published by crawler and cloud operators.
+package iprange
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Set is a binary trie of prefixes. IPv4 addresses are stored in their
+// IPv4-mapped IPv6 form so both families share one trie. A Set is safe for
+// concurrent reads once built.
+type Set struct {
+	root node
+	size int
+}
+
+type node struct {
+	children [2]*node
+	terminal bool
+}
+
+// NewSet returns a set containing the given prefixes.
+func NewSet(prefixes ...netip.Prefix) *Set {
+	s := &Set{}
+	for _, p := range prefixes {
+		s.Insert(p)
+	}
+	return s
+}
+
+// Insert adds a prefix to the set.
+func (s *Set) Insert(p netip.Prefix) {
+	addr, bits := normalize(p)
+	raw := addr.As16()
+
+	n := &s.root
+	for i := 0; i < bits; i++ {
+		if n.terminal {
+			return // already covered by a shorter prefix
+		}
+		bit := raw[i/8] >> (7 - uint(i%8)) & 1
+		if n.children[bit] == nil {
+			n.children[bit] = &node{}
+		}
+		n = n.children[bit]
+	}
+	if !n.terminal {
+		n.terminal = true
+		n.children = [2]*node{}
+		s.size++
+	}
+}
+
+// Contains reports whether addr falls in any prefix of the set.
+func (s *Set) Contains(addr netip.Addr) bool {
+	if s == nil || !addr.IsValid() {
+		return false
+	}
+	raw := addr.Unmap().As16() // IPv4 yields its IPv4-mapped form
+
+	n := &s.root
+	for i := 0; i < 128; i++ {
+		if n.terminal {
+			return true
+		}
+		n = n.children[raw[i/8]>>(7-uint(i%8))&1]
+		if n == nil {
+			return false
+		}
+	}
+	return n.terminal
+}
+
+// Len returns the number of distinct prefixes stored.
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return s.size
+}
+
+// normalize maps an IPv4 prefix into the IPv4-mapped IPv6 space.
+func normalize(p netip.Prefix) (netip.Addr, int) {
+	p = p.Masked()
+	if p.Addr().Is4() {
+		return netip.AddrFrom16(p.Addr().As16()), p.Bits() + 96
+	}
+	return p.Addr(), p.Bits()
+}
+
+// ParsePrefix accepts a CIDR or a bare address, which becomes a host prefix.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// LoadFile reads prefixes from a file. Files ending in .json use the
+// {"prefixes": [{"ipv4Prefix": ...}, {"ipv6Prefix": ...}]} layout published
+// by Google and Bing; anything else is one CIDR or address per line, with
+// "#" starting a comment.
+func LoadFile(path string) ([]netip.Prefix, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return parseJSON(f, path)
+	}
+	return parseLines(f, path)
+}
+
+func parseJSON(r io.Reader, path string) ([]netip.Prefix, error) {
+	var doc struct {
+		Prefixes []struct {
+			IPv4Prefix string `json:"ipv4Prefix"`
+			IPv6Prefix string `json:"ipv6Prefix"`
+		} `json:"prefixes"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	prefixes := make([]netip.Prefix, 0, len(doc.Prefixes))
+	for _, entry := range doc.Prefixes {
+		raw := entry.IPv4Prefix
+		if raw == "" {
+			raw = entry.IPv6Prefix
+		}
+		p, err := ParsePrefix(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix %q in %s: %w", raw, path, err)
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, nil
+}
+
+func parseLines(r io.Reader, path string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		p, err := ParsePrefix(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		prefixes = append(prefixes, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,77 @@
+package iprange
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetContains(t *testing.T) {
+	set := NewSet(
+		netip.MustParsePrefix("66.249.64.0/27"),
+		netip.MustParsePrefix("2001:4860:4801:10::/64"),
+		netip.MustParsePrefix("203.0.113.7/32"),
+	)
+
+	tests := []struct {
+		addr     string
+		expected bool
+	}{
+		{"66.249.64.1", true},
+		{"66.249.64.31", true},
+		{"66.249.64.32", false},
+		{"::ffff:66.249.64.5", true}, // IPv4-mapped
+		{"2001:4860:4801:10::1", true},
+		{"2001:4860:4801:11::1", false},
+		{"203.0.113.7", true},
+		{"203.0.113.8", false},
+	}
+
+	for _, tt := range tests {
+		if got := set.Contains(netip.MustParseAddr(tt.addr)); got != tt.expected {
+			t.Errorf("Contains(%s) = %v, want %v", tt.addr, got, tt.expected)
+		}
+	}
+
+	if set.Len() != 3 {
+		t.Errorf("Expected 3 prefixes, got %d", set.Len())
+	}
+}
+
+func TestSetInsert_CoveredPrefix(t *testing.T) {
+	set := NewSet(netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("10.1.0.0/16"))
+
+	if set.Len() != 1 {
+		t.Errorf("Expected covered prefix to be absorbed, got %d prefixes", set.Len())
+	}
+	if !set.Contains(netip.MustParseAddr("10.1.2.3")) {
+		t.Error("Expected 10.1.2.3 to be contained")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "googlebot.json")
+	_ = os.WriteFile(jsonPath, []byte(`{"prefixes":[{"ipv4Prefix":"66.249.64.0/27"},{"ipv6Prefix":"2001:4860:4801:10::/64"}]}`), 0o600)
+
+	textPath := filepath.Join(dir, "tor.txt")
+	_ = os.WriteFile(textPath, []byte("# exit nodes\n185.220.101.1\n\n198.51.100.0/24 # range\n"), 0o600)
+
+	for path, expected := range map[string]int{jsonPath: 2, textPath: 2} {
+		prefixes, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("LoadFile(%s) failed: %v", path, err)
+		}
+		if len(prefixes) != expected {
+			t.Errorf("LoadFile(%s) returned %d prefixes, want %d", path, len(prefixes), expected)
+		}
+	}
+
+	badPath := filepath.Join(dir, "bad.txt")
+	_ = os.WriteFile(badPath, []byte("not-an-ip\n"), 0o600)
+	if _, err := LoadFile(badPath); err == nil {
+		t.Error("Expected error for invalid line")
+	}
+}
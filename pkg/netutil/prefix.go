@@ -0,0 +1,63 @@
+// Package netutil resolves client addresses behind reverse proxies and masks
+// them to the network prefixes used for anonymization, subnet matching and
+// rate limiting.
+package netutil
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// Prefixes sets how many leading bits identify an address's network.
+type Prefixes struct {
+	V4 int
+	V6 int
+}
+
+// DefaultPrefixes keeps a /24 for IPv4 and a /48, the usual site
+// allocation, for IPv6.
+var DefaultPrefixes = Prefixes{V4: 24, V6: 48}
+
+// Validate checks that both lengths fit their address family.
+func (p Prefixes) Validate() error {
+	if p.V4 < 0 || p.V4 > 32 {
+		return fmt.Errorf("IPv4 prefix length must be between 0 and 32, got %d", p.V4)
+	}
+	if p.V6 < 0 || p.V6 > 128 {
+		return fmt.Errorf("IPv6 prefix length must be between 0 and 128, got %d", p.V6)
+	}
+	return nil
+}
+
+// Prefix masks addr to its network. IPv4-mapped IPv6 addresses are treated
+// as IPv4, and zones are dropped.
+func (p Prefixes) Prefix(addr netip.Addr) netip.Prefix {
+	addr = addr.Unmap().WithZone("")
+
+	bits := p.V6
+	if addr.Is4() {
+		bits = p.V4
+	}
+	prefix, _ := addr.Prefix(bits) // Only fails for invalid addrs or lengths
+	return prefix
+}
+
+// Anonymize returns ip with its host bits zeroed, or "" when ip is not an
+// address.
+func (p Prefixes) Anonymize(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	return p.Prefix(addr).Addr().String()
+}
+
+// Subnet returns the network of ip in CIDR notation, or "" when ip is not an
+// address.
+func (p Prefixes) Subnet(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	return p.Prefix(addr).String()
+}
@@ -0,0 +1,55 @@
+package netutil
+
+import "testing"
+
+func TestAnonymize(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected string
+	}{
+		{"192.168.1.100", "192.168.1.0"},
+		{"::ffff:192.168.1.100", "192.168.1.0"},
+		{"2001:db8:abcd:12:1:2:3:4", "2001:db8:abcd::"},
+		{"fe80::1%eth0", "fe80::"},
+		{"not-an-ip", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DefaultPrefixes.Anonymize(tt.ip); got != tt.expected {
+			t.Errorf("Anonymize(%q) = %q, want %q", tt.ip, got, tt.expected)
+		}
+	}
+}
+
+func TestSubnet(t *testing.T) {
+	p := Prefixes{V4: 16, V6: 64}
+
+	tests := []struct {
+		ip       string
+		expected string
+	}{
+		{"10.20.30.40", "10.20.0.0/16"},
+		{"::ffff:10.20.30.40", "10.20.0.0/16"},
+		{"2001:db8:abcd:12:1:2:3:4", "2001:db8:abcd:12::/64"},
+		{"garbage", ""},
+	}
+
+	for _, tt := range tests {
+		if got := p.Subnet(tt.ip); got != tt.expected {
+			t.Errorf("Subnet(%q) = %q, want %q", tt.ip, got, tt.expected)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := DefaultPrefixes.Validate(); err != nil {
+		t.Errorf("DefaultPrefixes.Validate() = %v", err)
+	}
+	if err := (Prefixes{V4: 33, V6: 48}).Validate(); err == nil {
+		t.Error("Expected error for /33 IPv4 prefix")
+	}
+	if err := (Prefixes{V4: 24, V6: 129}).Validate(); err == nil {
+		t.Error("Expected error for /129 IPv6 prefix")
+	}
+}
@@ -0,0 +1,123 @@
+package netutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/iamgideonidoko/signet/pkg/iprange"
+)
+
+// Forwarding headers understood by ProxyResolver.
+const (
+	HeaderForwarded     = "Forwarded"
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderXRealIP       = "X-Real-Ip"
+)
+
+// ProxyResolver finds the client address of a request that passed through
+// trusted reverse proxies. Only one header is read, the one the proxies are
+// known to set, so clients cannot pick a header the proxies leave untouched.
+type ProxyResolver struct {
+	header  string
+	trusted *iprange.Set
+}
+
+// NewProxyResolver trusts the given CIDRs or addresses to report the client
+// address in header. With no trusted proxies the peer address is always used.
+func NewProxyResolver(header string, trustedProxies []string) (*ProxyResolver, error) {
+	header = http.CanonicalHeaderKey(header)
+	switch header {
+	case HeaderForwarded, HeaderXForwardedFor, HeaderXRealIP:
+	default:
+		return nil, fmt.Errorf("unsupported client IP header %q", header)
+	}
+
+	trusted := iprange.NewSet()
+	for _, entry := range trustedProxies {
+		prefix, err := iprange.ParsePrefix(strings.TrimSpace(entry))
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		trusted.Insert(prefix)
+	}
+
+	return &ProxyResolver{header: header, trusted: trusted}, nil
+}
+
+// Header returns the forwarding header the resolver reads.
+func (r *ProxyResolver) Header() string {
+	return r.header
+}
+
+// Resolve walks the forwarding chain from the nearest hop outwards and
+// returns the first address that is not a trusted proxy. values are all
+// occurrences of the header, in order. A malformed hop ends the walk at the
+// last address seen, since nothing beyond it can be trusted.
+func (r *ProxyResolver) Resolve(remote netip.Addr, values []string) netip.Addr {
+	client := remote.Unmap()
+	if !r.trusted.Contains(client) {
+		return client
+	}
+
+	hops := r.hops(values)
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseHop(hops[i])
+		if !ok {
+			return client
+		}
+		client = addr
+		if !r.trusted.Contains(addr) {
+			break
+		}
+	}
+	return client
+}
+
+// hops splits the header values into one node per proxy hop.
+func (r *ProxyResolver) hops(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			if r.header == HeaderForwarded {
+				element = forwardedFor(element)
+			}
+			hops = append(hops, strings.TrimSpace(element))
+		}
+	}
+	return hops
+}
+
+// forwardedFor extracts the "for" parameter of an RFC 7239 element, or ""
+// when it has none.
+func forwardedFor(element string) string {
+	for _, pair := range strings.Split(element, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && strings.EqualFold(key, "for") {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseHop parses a node such as 192.0.2.1, "192.0.2.1:4711" or
+// "[2001:db8::1]:4711". Obfuscated and "unknown" nodes do not parse.
+func parseHop(node string) (netip.Addr, bool) {
+	node = strings.Trim(node, `"`)
+	if strings.HasPrefix(node, "[") {
+		end := strings.Index(node, "]")
+		if end < 0 {
+			return netip.Addr{}, false
+		}
+		node = node[1:end]
+	} else if strings.Count(node, ":") == 1 {
+		node, _, _ = strings.Cut(node, ":")
+	}
+
+	addr, err := netip.ParseAddr(node)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
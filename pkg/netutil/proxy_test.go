@@ -0,0 +1,61 @@
+package netutil
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "2001:db8:ffff::1"}
+
+	xff, err := NewProxyResolver("x-forwarded-for", trusted)
+	if err != nil {
+		t.Fatalf("NewProxyResolver() failed: %v", err)
+	}
+	forwarded, err := NewProxyResolver("Forwarded", trusted)
+	if err != nil {
+		t.Fatalf("NewProxyResolver() failed: %v", err)
+	}
+	realIP, err := NewProxyResolver("X-Real-IP", trusted)
+	if err != nil {
+		t.Fatalf("NewProxyResolver() failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		resolver *ProxyResolver
+		remote   string
+		values   []string
+		expected string
+	}{
+		{"untrusted peer ignores header", xff, "203.0.113.9", []string{"198.51.100.1"}, "203.0.113.9"},
+		{"single hop", xff, "10.0.0.2", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"spoofed leftmost entry", xff, "10.0.0.2", []string{"1.1.1.1, 198.51.100.1, 10.0.0.3"}, "198.51.100.1"},
+		{"repeated headers", xff, "10.0.0.2", []string{"1.1.1.1", "198.51.100.1"}, "198.51.100.1"},
+		{"all hops trusted", xff, "10.0.0.2", []string{"10.0.0.5, 10.0.0.3"}, "10.0.0.5"},
+		{"malformed hop", xff, "10.0.0.2", []string{"198.51.100.1, garbage"}, "10.0.0.2"},
+		{"no header", xff, "10.0.0.2", nil, "10.0.0.2"},
+		{"v4-mapped peer", xff, "::ffff:10.0.0.2", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"v6 proxy", xff, "2001:db8:ffff::1", []string{"2001:db8:1::7"}, "2001:db8:1::7"},
+		{"forwarded with ports", forwarded, "10.0.0.2",
+			[]string{`for=192.0.2.60:8080;proto=https, for="[2001:db8:cafe::17]:4711";by=10.0.0.2`}, "2001:db8:cafe::17"},
+		{"forwarded obfuscated", forwarded, "10.0.0.2", []string{"for=_hidden"}, "10.0.0.2"},
+		{"real ip", realIP, "10.0.0.2", []string{"198.51.100.1"}, "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		got := tt.resolver.Resolve(netip.MustParseAddr(tt.remote), tt.values)
+		if got != netip.MustParseAddr(tt.expected) {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestNewProxyResolver_Invalid(t *testing.T) {
+	if _, err := NewProxyResolver("X-Client-IP", nil); err == nil {
+		t.Error("Expected error for unsupported header")
+	}
+	if _, err := NewProxyResolver(HeaderXForwardedFor, []string{"10.0.0.0/33"}); err == nil {
+		t.Error("Expected error for invalid CIDR")
+	}
+}
@@ -0,0 +1,55 @@
+package sealed
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// ParseKey parses a signing key spec of the form "id:alg:base64", where alg
+// is "hs256" (raw secret) or "ed25519" (32-byte seed).
+func ParseKey(spec string) (Key, error) {
+	parts := strings.SplitN(spec, ":", 3)
+	if len(parts) != 3 || parts[0] == "" {
+		return Key{}, fmt.Errorf("sealed: invalid key spec %q", redact(spec))
+	}
+
+	material, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Key{}, fmt.Errorf("sealed: key %q is not valid base64", parts[0])
+	}
+
+	switch strings.ToLower(parts[1]) {
+	case "hs256":
+		if len(material) < 32 {
+			return Key{}, fmt.Errorf("sealed: hs256 key %q must be at least 32 bytes", parts[0])
+		}
+		return NewHMACKey(parts[0], material), nil
+	case "ed25519":
+		return NewEd25519Key(parts[0], material)
+	default:
+		return Key{}, fmt.Errorf("sealed: key %q has unsupported algorithm %q", parts[0], parts[1])
+	}
+}
+
+// ParseEncryptionKey parses an encryption key spec of the form "id:base64".
+func ParseEncryptionKey(spec string) (EncryptionKey, error) {
+	id, encoded, ok := strings.Cut(spec, ":")
+	if !ok || id == "" {
+		return EncryptionKey{}, fmt.Errorf("sealed: invalid encryption key spec %q", redact(spec))
+	}
+
+	material, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return EncryptionKey{}, fmt.Errorf("sealed: encryption key %q is not valid base64", id)
+	}
+	return NewEncryptionKey(id, material)
+}
+
+// redact keeps key material out of error messages.
+func redact(spec string) string {
+	if id, _, ok := strings.Cut(spec, ":"); ok {
+		return id + ":***"
+	}
+	return "***"
+}
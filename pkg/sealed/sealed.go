@@ -0,0 +1,307 @@
+// Package sealed produces and verifies signed, optionally encrypted
+// identification results that a browser can relay to a backend without
+// being able to forge them.
+//
+// A sealed token has three base64url segments, "header.body.signature".
+// The header names the signing key and, when the body is encrypted, the
+// encryption key. The signature covers "header.body".
+package sealed
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Algorithm identifies how a token is signed.
+type Algorithm string
+
+const (
+	HS256 Algorithm = "HS256"
+	EdDSA Algorithm = "EdDSA"
+)
+
+// encA256GCM marks a body encrypted with AES-256-GCM.
+const encA256GCM = "A256GCM"
+
+var (
+	ErrMalformed        = errors.New("sealed: malformed token")
+	ErrUnknownKey       = errors.New("sealed: unknown key")
+	ErrInvalidSignature = errors.New("sealed: invalid signature")
+	ErrExpired          = errors.New("sealed: token expired")
+)
+
+var b64 = base64.RawURLEncoding
+
+// Payload is the identification result carried inside a token.
+type Payload struct {
+	VisitorID  uuid.UUID `json:"visitor_id"`
+	RequestID  uuid.UUID `json:"request_id"`
+	Confidence float64   `json:"confidence"`
+	BotVerdict string    `json:"bot_verdict"`
+	Timestamp  int64     `json:"timestamp"` // Unix milliseconds
+}
+
+// Time returns the moment the payload was sealed.
+func (p Payload) Time() time.Time {
+	return time.UnixMilli(p.Timestamp)
+}
+
+type header struct {
+	Alg   Algorithm `json:"alg"`
+	KID   string    `json:"kid"`
+	Enc   string    `json:"enc,omitempty"`
+	EncID string    `json:"ekid,omitempty"`
+}
+
+// Sealer signs payloads with a single active key and optionally encrypts them.
+type Sealer struct {
+	key        Key
+	encryption *EncryptionKey
+}
+
+// NewSealer returns a sealer for the given signing key. A nil encryption key
+// produces signed but readable tokens.
+func NewSealer(key Key, encryption *EncryptionKey) (*Sealer, error) {
+	if !key.canSign() {
+		return nil, fmt.Errorf("sealed: key %q cannot sign", key.ID)
+	}
+	return &Sealer{key: key, encryption: encryption}, nil
+}
+
+// Seal serializes, optionally encrypts, and signs the payload.
+func (s *Sealer) Seal(p Payload) (string, error) {
+	if p.Timestamp == 0 {
+		p.Timestamp = time.Now().UnixMilli()
+	}
+
+	h := header{Alg: s.key.Algorithm, KID: s.key.ID}
+	if s.encryption != nil {
+		h.Enc = encA256GCM
+		h.EncID = s.encryption.ID
+	}
+
+	headerJSON, err := json.Marshal(h)
+	if err != nil {
+		return "", fmt.Errorf("sealed: failed to marshal header: %w", err)
+	}
+	headerSeg := b64.EncodeToString(headerJSON)
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("sealed: failed to marshal payload: %w", err)
+	}
+	if s.encryption != nil {
+		if body, err = s.encryption.encrypt(body, []byte(headerSeg)); err != nil {
+			return "", err
+		}
+	}
+
+	signingInput := headerSeg + "." + b64.EncodeToString(body)
+	return signingInput + "." + b64.EncodeToString(s.key.sign([]byte(signingInput))), nil
+}
+
+// Verifier checks tokens against a set of keys, so tokens signed before a key
+// rotation keep verifying while the old key is still listed.
+type Verifier struct {
+	keys       map[string]Key
+	encryption map[string]EncryptionKey
+}
+
+// NewVerifier returns a verifier accepting any of the given keys.
+func NewVerifier(keys []Key, encryptionKeys []EncryptionKey) *Verifier {
+	v := &Verifier{
+		keys:       make(map[string]Key, len(keys)),
+		encryption: make(map[string]EncryptionKey, len(encryptionKeys)),
+	}
+	for _, k := range keys {
+		v.keys[k.ID] = k
+	}
+	for _, k := range encryptionKeys {
+		v.encryption[k.ID] = k
+	}
+	return v
+}
+
+// Verify checks the token signature, decrypts the body if needed and returns
+// the payload. A positive maxAge rejects tokens sealed longer ago than that.
+func (v *Verifier) Verify(token string, maxAge time.Duration) (*Payload, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	headerJSON, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	var h header
+	if err := json.Unmarshal(headerJSON, &h); err != nil {
+		return nil, ErrMalformed
+	}
+
+	key, ok := v.keys[h.KID]
+	if !ok || key.Algorithm != h.Alg {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, h.KID)
+	}
+
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if !key.verify([]byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrInvalidSignature
+	}
+
+	body, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if h.Enc != "" {
+		encKey, ok := v.encryption[h.EncID]
+		if !ok || h.Enc != encA256GCM {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKey, h.EncID)
+		}
+		if body, err = encKey.decrypt(body, []byte(parts[0])); err != nil {
+			return nil, err
+		}
+	}
+
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, ErrMalformed
+	}
+
+	if maxAge > 0 && time.Since(p.Time()) > maxAge {
+		return nil, ErrExpired
+	}
+
+	return &p, nil
+}
+
+// Key is a signing or verification key identified by ID.
+type Key struct {
+	ID         string
+	Algorithm  Algorithm
+	secret     []byte
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewHMACKey returns an HS256 key. The secret should be at least 32 bytes.
+func NewHMACKey(id string, secret []byte) Key {
+	return Key{ID: id, Algorithm: HS256, secret: secret}
+}
+
+// NewEd25519Key returns an EdDSA signing key from a 32-byte seed.
+func NewEd25519Key(id string, seed []byte) (Key, error) {
+	if len(seed) != ed25519.SeedSize {
+		return Key{}, fmt.Errorf("sealed: ed25519 seed must be %d bytes", ed25519.SeedSize)
+	}
+	priv := ed25519.NewKeyFromSeed(seed)
+	pub, _ := priv.Public().(ed25519.PublicKey)
+	return Key{ID: id, Algorithm: EdDSA, privateKey: priv, publicKey: pub}, nil
+}
+
+// NewEd25519PublicKey returns a verification-only EdDSA key.
+func NewEd25519PublicKey(id string, pub []byte) (Key, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return Key{}, fmt.Errorf("sealed: ed25519 public key must be %d bytes", ed25519.PublicKeySize)
+	}
+	return Key{ID: id, Algorithm: EdDSA, publicKey: ed25519.PublicKey(pub)}, nil
+}
+
+// PublicKey returns the EdDSA public key, or nil for HMAC keys.
+func (k Key) PublicKey() ed25519.PublicKey {
+	return k.publicKey
+}
+
+func (k Key) canSign() bool {
+	switch k.Algorithm {
+	case HS256:
+		return len(k.secret) > 0
+	case EdDSA:
+		return k.privateKey != nil
+	}
+	return false
+}
+
+func (k Key) sign(input []byte) []byte {
+	if k.Algorithm == EdDSA {
+		return ed25519.Sign(k.privateKey, input)
+	}
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write(input)
+	return mac.Sum(nil)
+}
+
+func (k Key) verify(input, sig []byte) bool {
+	switch k.Algorithm {
+	case HS256:
+		return len(k.secret) > 0 && hmac.Equal(k.sign(input), sig)
+	case EdDSA:
+		return k.publicKey != nil && ed25519.Verify(k.publicKey, input, sig)
+	}
+	return false
+}
+
+// EncryptionKey is an AES-256 key used to hide the payload from the browser.
+type EncryptionKey struct {
+	ID  string
+	key []byte
+}
+
+// NewEncryptionKey returns an AES-256-GCM key from 32 raw bytes.
+func NewEncryptionKey(id string, key []byte) (EncryptionKey, error) {
+	if len(key) != 32 {
+		return EncryptionKey{}, errors.New("sealed: encryption key must be 32 bytes")
+	}
+	return EncryptionKey{ID: id, key: key}, nil
+}
+
+func (k EncryptionKey) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.key)
+	if err != nil {
+		return nil, fmt.Errorf("sealed: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (k EncryptionKey) encrypt(plaintext, aad []byte) ([]byte, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("sealed: failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func (k EncryptionKey) decrypt(ciphertext, aad []byte) ([]byte, error) {
+	aead, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, aad)
+	if err != nil {
+		return nil, fmt.Errorf("sealed: failed to decrypt payload: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,134 @@
+package sealed
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testPayload() Payload {
+	return Payload{
+		VisitorID:  uuid.New(),
+		RequestID:  uuid.New(),
+		Confidence: 0.92,
+		BotVerdict: "human",
+	}
+}
+
+func TestSealAndVerify_HMAC(t *testing.T) {
+	key := NewHMACKey("k1", bytes.Repeat([]byte("s"), 32))
+	sealer, err := NewSealer(key, nil)
+	if err != nil {
+		t.Fatalf("NewSealer() failed: %v", err)
+	}
+
+	p := testPayload()
+	token, err := sealer.Seal(p)
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+
+	got, err := NewVerifier([]Key{key}, nil).Verify(token, time.Minute)
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	if got.VisitorID != p.VisitorID || got.RequestID != p.RequestID || got.Confidence != p.Confidence {
+		t.Errorf("Verify() returned %+v, want %+v", got, p)
+	}
+}
+
+func TestSealAndVerify_Ed25519Encrypted(t *testing.T) {
+	signing, err := NewEd25519Key("ed1", bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewEd25519Key() failed: %v", err)
+	}
+	enc, err := NewEncryptionKey("e1", bytes.Repeat([]byte{9}, 32))
+	if err != nil {
+		t.Fatalf("NewEncryptionKey() failed: %v", err)
+	}
+
+	sealer, _ := NewSealer(signing, &enc)
+	p := testPayload()
+	token, err := sealer.Seal(p)
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+
+	body, _ := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+	if bytes.Contains(body, []byte(p.VisitorID.String())) {
+		t.Error("Encrypted token body should not contain the visitor ID")
+	}
+
+	// Backends only need the public half of the signing key.
+	public, _ := NewEd25519PublicKey("ed1", signing.PublicKey())
+	got, err := NewVerifier([]Key{public}, []EncryptionKey{enc}).Verify(token, 0)
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	if got.VisitorID != p.VisitorID {
+		t.Errorf("Expected visitor %s, got %s", p.VisitorID, got.VisitorID)
+	}
+}
+
+func TestVerify_Rejections(t *testing.T) {
+	key := NewHMACKey("k1", bytes.Repeat([]byte("a"), 32))
+	other := NewHMACKey("k1", bytes.Repeat([]byte("b"), 32))
+	sealer, _ := NewSealer(key, nil)
+
+	token, _ := sealer.Seal(testPayload())
+	stale, _ := sealer.Seal(Payload{Timestamp: time.Now().Add(-time.Hour).UnixMilli()})
+
+	tests := []struct {
+		name     string
+		verifier *Verifier
+		token    string
+		want     error
+	}{
+		{"wrong secret", NewVerifier([]Key{other}, nil), token, ErrInvalidSignature},
+		{"unknown key id", NewVerifier([]Key{NewHMACKey("k2", key.secret)}, nil), token, ErrUnknownKey},
+		{"tampered body", NewVerifier([]Key{key}, nil), tamper(token), ErrInvalidSignature},
+		{"expired", NewVerifier([]Key{key}, nil), stale, ErrExpired},
+		{"malformed", NewVerifier([]Key{key}, nil), "not-a-token", ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		_, err := tt.verifier.Verify(tt.token, time.Minute)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte("x"), 32))
+
+	key, err := ParseKey("k1:hs256:" + secret)
+	if err != nil {
+		t.Fatalf("ParseKey() failed: %v", err)
+	}
+	if key.ID != "k1" || key.Algorithm != HS256 {
+		t.Errorf("Unexpected key %q/%s", key.ID, key.Algorithm)
+	}
+
+	if _, err := ParseKey("k1:hs256:" + base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("Expected error for short HMAC secret")
+	}
+
+	_, err = ParseKey("k1:rsa:" + secret)
+	if err == nil || strings.Contains(err.Error(), secret) {
+		t.Errorf("Expected redacted error for unsupported algorithm, got %v", err)
+	}
+}
+
+func tamper(token string) string {
+	parts := strings.Split(token, ".")
+	body, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	body = bytes.Replace(body, []byte(`"bot_verdict":"human"`), []byte(`"bot_verdict":"robot"`), 1)
+	parts[1] = base64.RawURLEncoding.EncodeToString(body)
+	return strings.Join(parts, ".")
+}
@@ -1,10 +1,12 @@
 package similarity
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"math"
+	"slices"
 	"sort"
 	"strings"
 
@@ -32,57 +34,167 @@ type FeatureVector struct {
 
 // Calculator computes similarity between fingerprints.
 type Calculator struct {
-	weights Weights
+	weights    Weights
+	categories map[string]float64 // Weight per feature key category
 }
 
 func NewCalculator(weights Weights) *Calculator {
-	return &Calculator{weights: weights}
+	return &Calculator{
+		weights: weights,
+		categories: map[string]float64{
+			"canvas":         weights.Hardware,
+			"audio":          weights.Hardware,
+			"webgl":          weights.Hardware,
+			"webgl_ext":      weights.Hardware * 0.7,
+			"hw_concurrency": weights.Hardware * 0.6,
+			"device_memory":  weights.Hardware * 0.6,
+			"color_depth":    weights.Hardware * 0.5,
+			"tz":             weights.Environment,
+			"lang":           weights.Environment,
+			"fonts":          weights.Environment * 0.9,
+			"screen":         weights.Environment * 0.7,
+			"platform":       weights.Software,
+			"browser":        weights.Software,
+		},
+	}
 }
 
 // ExtractFeatures converts signals into a weighted feature vector.
 func (c *Calculator) ExtractFeatures(signals models.Signals) FeatureVector {
-	features := make(map[string]float64)
+	return c.Vector(FeatureKeys(signals))
+}
+
+// FeatureKeys reduces signals to "category:value" keys. High-cardinality
+// values are hashed, so the keys can be stored in place of raw signals and
+// weighted later with Vector.
+func FeatureKeys(signals models.Signals) []string {
+	var keys []string
 
 	if signals.Canvas2DHash != "" {
-		features["canvas:"+signals.Canvas2DHash] = c.weights.Hardware
+		keys = append(keys, "canvas:"+signals.Canvas2DHash)
 	}
 	if signals.AudioHash != "" {
-		features["audio:"+signals.AudioHash] = c.weights.Hardware
+		keys = append(keys, "audio:"+signals.AudioHash)
 	}
-	features[fmt.Sprintf("webgl:%s:%s", signals.WebGLVendor, signals.WebGLRenderer)] = c.weights.Hardware
-
-	extHash := hashStringSlice(signals.WebGLExtensions)
-	features["webgl_ext:"+extHash] = c.weights.Hardware * 0.7
-
-	features[fmt.Sprintf("hw_concurrency:%d", signals.HardwareConcurrency)] = c.weights.Hardware * 0.6
-	features[fmt.Sprintf("device_memory:%.0f", signals.DeviceMemory)] = c.weights.Hardware * 0.6
-	features[fmt.Sprintf("color_depth:%d", signals.ColorDepth)] = c.weights.Hardware * 0.5
+	keys = append(keys,
+		fmt.Sprintf("webgl:%s:%s", signals.WebGLVendor, signals.WebGLRenderer),
+		"webgl_ext:"+hashStringSlice(signals.WebGLExtensions),
+		fmt.Sprintf("hw_concurrency:%d", signals.HardwareConcurrency),
+		fmt.Sprintf("device_memory:%.0f", signals.DeviceMemory),
+		fmt.Sprintf("color_depth:%d", signals.ColorDepth),
+	)
 
 	if signals.TimeZone != "" {
-		features["tz:"+signals.TimeZone] = c.weights.Environment
+		keys = append(keys, "tz:"+signals.TimeZone)
 	}
-	langHash := hashStringSlice(signals.Languages)
-	features["lang:"+langHash] = c.weights.Environment
-
-	fontHash := hashStringSlice(signals.Fonts)
-	features["fonts:"+fontHash] = c.weights.Environment * 0.9
-
-	features[fmt.Sprintf("screen:%dx%d", signals.ScreenWidth, signals.ScreenHeight)] = c.weights.Environment * 0.7
+	keys = append(keys,
+		"lang:"+hashStringSlice(signals.Languages),
+		"fonts:"+hashStringSlice(signals.Fonts),
+		fmt.Sprintf("screen:%dx%d", signals.ScreenWidth, signals.ScreenHeight),
+	)
 
 	if signals.Platform != "" {
-		features["platform:"+signals.Platform] = c.weights.Software
+		keys = append(keys, "platform:"+signals.Platform)
 	}
 
 	browserVersion := extractBrowserVersion(signals.UserAgent)
 	if browserVersion != "" {
-		features["browser:"+browserVersion] = c.weights.Software
+		keys = append(keys, "browser:"+browserVersion)
+	}
+
+	return keys
+}
+
+// WithoutCategories drops keys of the given categories, such as the
+// signal groups an entropy budget cleared, whose placeholder keys would
+// otherwise make unrelated visitors look alike.
+func WithoutCategories(keys, categories []string) []string {
+	if len(categories) == 0 {
+		return keys
+	}
+	kept := make([]string, 0, len(keys))
+	for _, key := range keys {
+		category, _, _ := strings.Cut(key, ":")
+		if !slices.Contains(categories, category) {
+			kept = append(kept, key)
+		}
+	}
+	return kept
+}
+
+// HashFeatureKeys replaces the value of each "category:value" key with a
+// truncated HMAC-SHA256 keyed by secret. The category is kept, so hashed
+// keys weight the same under Vector and compare with each other like the
+// originals. Without the secret, values from small domains such as time
+// zones cannot be recovered by hashing every candidate. Keys hashed under
+// different secrets never match.
+func HashFeatureKeys(secret []byte, keys []string) []string {
+	hashed := make([]string, len(keys))
+	for i, key := range keys {
+		category, value, _ := strings.Cut(key, ":")
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(value))
+		hashed[i] = category + ":#" + hex.EncodeToString(mac.Sum(nil)[:8])
 	}
+	return hashed
+}
+
+// NormalizeFeatureKeys hashes the keys HashFeatureKeys has not hashed yet,
+// so raw keys and keys stored hashed under the same secret count as the
+// same values.
+func NormalizeFeatureKeys(secret []byte, keys []string) []string {
+	normalized := make([]string, len(keys))
+	for i, key := range keys {
+		if _, value, _ := strings.Cut(key, ":"); isHashedValue(value) {
+			normalized[i] = key
+			continue
+		}
+		normalized[i] = HashFeatureKeys(secret, []string{key})[0]
+	}
+	return normalized
+}
+
+// isHashedValue reports whether value has the form HashFeatureKeys gives it.
+func isHashedValue(value string) bool {
+	if len(value) != 17 || value[0] != '#' {
+		return false
+	}
+	_, err := hex.DecodeString(value[1:])
+	return err == nil
+}
 
-	hash := c.computeVectorHash(features)
+// CategoryWeights returns the weight of each feature key category.
+func (c *Calculator) CategoryWeights() map[string]float64 {
+	weights := make(map[string]float64, len(c.categories))
+	for category, w := range c.categories {
+		weights[category] = w
+	}
+	return weights
+}
+
+// FeatureSetHash identifies an exact set of feature keys regardless of
+// their order, so visitors reporting identical features share a hash.
+func FeatureSetHash(keys []string) string {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "|")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Vector weights feature keys by their category. Keys of unknown
+// categories are ignored.
+func (c *Calculator) Vector(keys []string) FeatureVector {
+	features := make(map[string]float64, len(keys))
+	for _, key := range keys {
+		category, _, _ := strings.Cut(key, ":")
+		if w, ok := c.categories[category]; ok {
+			features[key] = w
+		}
+	}
 
 	return FeatureVector{
 		Features: features,
-		Hash:     hash,
+		Hash:     c.computeVectorHash(features),
 	}
 }
 
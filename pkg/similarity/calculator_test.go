@@ -1,6 +1,7 @@
 package similarity
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/iamgideonidoko/signet/internal/models"
@@ -88,6 +89,19 @@ func TestJaccardSimilarity_HardwareChange(t *testing.T) {
 	}
 }
 
+func TestWithoutCategories(t *testing.T) {
+	keys := FeatureKeys(models.Signals{TimeZone: "Europe/Berlin", ScreenWidth: 1920, ScreenHeight: 1080})
+
+	if got := WithoutCategories(keys, nil); len(got) != len(keys) {
+		t.Errorf("Expected all %d keys without categories, got %v", len(keys), got)
+	}
+
+	got := WithoutCategories(keys, []string{"webgl", "webgl_ext", "hw_concurrency", "device_memory", "color_depth", "lang", "fonts"})
+	if len(got) != 2 || got[0] != "tz:Europe/Berlin" || got[1] != "screen:1920x1080" {
+		t.Errorf("Expected only tz and screen keys, got %v", got)
+	}
+}
+
 func TestComputeHardwareHash_Consistency(t *testing.T) {
 	signals := models.Signals{
 		Canvas2DHash:        "abc123",
@@ -136,3 +150,108 @@ func TestExtractBrowserVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestVector_MatchesExtractFeatures(t *testing.T) {
+	calc := NewCalculator(DefaultWeights)
+
+	signals := models.Signals{
+		Canvas2DHash:  "abc123",
+		WebGLVendor:   "NVIDIA",
+		WebGLRenderer: "GeForce GTX 1080",
+		TimeZone:      "Europe/Berlin",
+		UserAgent:     "Mozilla/5.0 Chrome/120.0.0.0",
+	}
+
+	stored := calc.Vector(append(FeatureKeys(signals), "unknown:ignored"))
+	extracted := calc.ExtractFeatures(signals)
+
+	if stored.Hash != extracted.Hash || len(stored.Features) != len(extracted.Features) {
+		t.Errorf("Vector from stored keys differs from extracted features: %v vs %v", stored.Features, extracted.Features)
+	}
+}
+
+func TestHashFeatureKeys_PreservesSimilarity(t *testing.T) {
+	calc := NewCalculator(DefaultWeights)
+
+	signals1 := models.Signals{
+		Canvas2DHash:  "abc123",
+		WebGLVendor:   "NVIDIA",
+		WebGLRenderer: "GeForce GTX 1080",
+		TimeZone:      "Europe/Berlin",
+		UserAgent:     "Mozilla/5.0 Chrome/120.0.0.0",
+	}
+	signals2 := signals1
+	signals2.UserAgent = "Mozilla/5.0 Chrome/121.0.0.0"
+
+	keys1, keys2 := FeatureKeys(signals1), FeatureKeys(signals2)
+	secret := []byte("feature-hash-secret")
+	hashed1, hashed2 := HashFeatureKeys(secret, keys1), HashFeatureKeys(secret, keys2)
+
+	for i, key := range hashed1 {
+		if key == keys1[i] {
+			t.Errorf("Key %q was not hashed", key)
+		}
+	}
+
+	raw := calc.JaccardSimilarity(calc.Vector(keys1), calc.Vector(keys2))
+	hashed := calc.JaccardSimilarity(calc.Vector(hashed1), calc.Vector(hashed2))
+	if raw != hashed {
+		t.Errorf("Hashing changed similarity: %.4f vs %.4f", raw, hashed)
+	}
+}
+
+func TestHashFeatureKeys_DependsOnSecret(t *testing.T) {
+	keys := []string{"tz:Europe/Berlin", "hw_concurrency:8"}
+
+	a := HashFeatureKeys([]byte("secret-a"), keys)
+	b := HashFeatureKeys([]byte("secret-b"), keys)
+	for i := range keys {
+		if a[i] == b[i] {
+			t.Errorf("Key %q hashed the same under different secrets", keys[i])
+		}
+		if !strings.HasPrefix(a[i], strings.SplitN(keys[i], ":", 2)[0]+":#") {
+			t.Errorf("Hashed key %q lost its category", a[i])
+		}
+	}
+
+	if again := HashFeatureKeys([]byte("secret-a"), keys); again[0] != a[0] {
+		t.Error("Hashing should be deterministic for a secret")
+	}
+}
+
+func TestNormalizeFeatureKeys(t *testing.T) {
+	secret := []byte("secret")
+	raw := []string{"tz:Europe/Berlin", "hw_concurrency:8", "webgl:NVIDIA:GeForce"}
+	hashed := HashFeatureKeys(secret, raw)
+
+	mixed := []string{raw[0], hashed[1], raw[2]}
+	got := NormalizeFeatureKeys(secret, mixed)
+	for i := range hashed {
+		if got[i] != hashed[i] {
+			t.Errorf("NormalizeFeatureKeys(%q) = %q, want %q", mixed[i], got[i], hashed[i])
+		}
+	}
+	if again := NormalizeFeatureKeys(secret, got); FeatureSetHash(again) != FeatureSetHash(hashed) {
+		t.Error("Normalizing hashed keys should leave them unchanged")
+	}
+}
+
+func TestFeatureSetHash(t *testing.T) {
+	keys := FeatureKeys(models.Signals{
+		Canvas2DHash: "abc123",
+		TimeZone:     "Europe/Berlin",
+		ScreenWidth:  1920,
+		ScreenHeight: 1080,
+	})
+	reversed := make([]string, len(keys))
+	for i, key := range keys {
+		reversed[len(keys)-1-i] = key
+	}
+
+	if FeatureSetHash(keys) != FeatureSetHash(reversed) {
+		t.Error("Hash should not depend on key order")
+	}
+	if FeatureSetHash(keys) == FeatureSetHash(keys[1:]) {
+		t.Error("Different key sets should hash differently")
+	}
+}
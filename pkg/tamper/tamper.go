@@ -0,0 +1,140 @@
+// Package tamper flags combinations of signals that cannot occur on a real
+// device. Anti-detect browsers randomize each field to a plausible value, but
+// rarely keep the fields consistent with each other.
+package tamper
+
+import (
+	"strings"
+	"time"
+	_ "time/tzdata" // timezone checks must not depend on the host's zoneinfo
+
+	"github.com/iamgideonidoko/signet/internal/models"
+	"github.com/iamgideonidoko/signet/pkg/useragent"
+)
+
+// Inconsistency codes.
+const (
+	CodePlatformUAMismatch     = "platform_ua_mismatch"
+	CodeMacDirect3D            = "mac_direct3d_renderer"
+	CodeWindowsAppleGPU        = "windows_apple_gpu"
+	CodeIOSCoreCount           = "ios_core_count"
+	CodeDesktopTouch           = "desktop_touch_points"
+	CodeMobileNoTouch          = "mobile_without_touch"
+	CodeScreenSmallerThanAvail = "screen_smaller_than_avail"
+	CodeTimezoneOffsetMismatch = "timezone_offset_mismatch"
+	CodeUnknownTimezone        = "unknown_timezone"
+)
+
+// maxIOSCores is above any shipping iPhone or iPad.
+const maxIOSCores = 10
+
+// Check is one consistency rule.
+type Check struct {
+	Code     string
+	Severity models.Severity
+	Match    func(s models.Signals, ua useragent.Info) bool
+}
+
+// DefaultChecks returns the built-in consistency rules.
+func DefaultChecks() []Check {
+	return []Check{
+		{CodePlatformUAMismatch, models.SeverityHigh, platformUAMismatch},
+		{CodeMacDirect3D, models.SeverityHigh, func(s models.Signals, _ useragent.Info) bool {
+			return strings.HasPrefix(s.Platform, "Mac") && strings.Contains(s.WebGLRenderer, "Direct3D")
+		}},
+		{CodeWindowsAppleGPU, models.SeverityHigh, func(s models.Signals, _ useragent.Info) bool {
+			return strings.HasPrefix(s.Platform, "Win") &&
+				(strings.Contains(s.WebGLRenderer, "Apple M") || strings.Contains(s.WebGLRenderer, "Metal"))
+		}},
+		{CodeIOSCoreCount, models.SeverityHigh, func(s models.Signals, ua useragent.Info) bool {
+			return ua.OS == useragent.IOS && s.HardwareConcurrency > maxIOSCores
+		}},
+		// Windows and ChromeOS touch laptops are common, and iPads in desktop
+		// mode send a Mac UA, so only Linux desktops are checked.
+		{CodeDesktopTouch, models.SeverityLow, func(s models.Signals, ua useragent.Info) bool {
+			return ua.OS == useragent.Linux && !ua.Mobile && s.MaxTouchPoints > 0
+		}},
+		{CodeMobileNoTouch, models.SeverityMedium, func(s models.Signals, ua useragent.Info) bool {
+			return ua.Mobile && s.MaxTouchPoints == 0
+		}},
+		{CodeScreenSmallerThanAvail, models.SeverityHigh, func(s models.Signals, _ useragent.Info) bool {
+			return s.AvailWidth > s.ScreenWidth || s.AvailHeight > s.ScreenHeight
+		}},
+		{CodeUnknownTimezone, models.SeverityMedium, func(s models.Signals, _ useragent.Info) bool {
+			if s.TimeZone == "" {
+				return false
+			}
+			_, err := time.LoadLocation(s.TimeZone)
+			return err != nil
+		}},
+		{CodeTimezoneOffsetMismatch, models.SeverityMedium, timezoneOffsetMismatch},
+	}
+}
+
+// Checker runs a set of consistency checks.
+type Checker struct {
+	checks []Check
+}
+
+func NewChecker(checks []Check) *Checker {
+	return &Checker{checks: checks}
+}
+
+// Detect returns every inconsistency found in the signals, in check order.
+func (c *Checker) Detect(signals models.Signals) []models.Inconsistency {
+	ua := useragent.Parse(signals.UserAgent)
+
+	var found []models.Inconsistency
+	for _, check := range c.checks {
+		if check.Match(signals, ua) {
+			found = append(found, models.Inconsistency{Code: check.Code, Severity: check.Severity})
+		}
+	}
+	return found
+}
+
+// platformUAMismatch compares navigator.platform with the OS in the UA.
+func platformUAMismatch(s models.Signals, ua useragent.Info) bool {
+	if s.Platform == "" || ua.OS == useragent.Unknown {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(s.Platform, "Win"):
+		return ua.OS != useragent.Windows
+	case strings.HasPrefix(s.Platform, "Mac"):
+		// iPads request desktop sites with a Mac platform and UA.
+		return ua.OS != useragent.MacOS && ua.OS != useragent.IOS
+	case strings.HasPrefix(s.Platform, "iPhone"), strings.HasPrefix(s.Platform, "iPad"):
+		return ua.OS != useragent.IOS
+	case strings.HasPrefix(s.Platform, "Linux"):
+		return ua.OS != useragent.Linux && ua.OS != useragent.Android && ua.OS != useragent.ChromeOS
+	}
+	return false
+}
+
+// timezoneOffsetMismatch checks the reported UTC offset against the IANA
+// zone. JavaScript reports minutes west of UTC, and the offset may be taken
+// in either standard or daylight time, so both are accepted.
+func timezoneOffsetMismatch(s models.Signals, _ useragent.Info) bool {
+	if s.TimeZone == "" {
+		return false
+	}
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return false // reported by CodeUnknownTimezone
+	}
+
+	year := time.Now().Year()
+	for _, t := range []time.Time{
+		time.Now(),
+		time.Date(year, time.January, 1, 12, 0, 0, 0, loc),
+		time.Date(year, time.July, 1, 12, 0, 0, 0, loc),
+	} {
+		_, offset := t.In(loc).Zone()
+		if -offset/60 == s.TimezoneOffset {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package tamper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iamgideonidoko/signet/internal/models"
+)
+
+const (
+	macChromeUA = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	iphoneUA    = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1"
+)
+
+func macSignals() models.Signals {
+	return models.Signals{
+		UserAgent:           macChromeUA,
+		Platform:            "MacIntel",
+		WebGLRenderer:       "ANGLE (Apple, ANGLE Metal Renderer: Apple M2, Unspecified Version)",
+		HardwareConcurrency: 8,
+		ScreenWidth:         1512,
+		ScreenHeight:        982,
+		AvailWidth:          1512,
+		AvailHeight:         944,
+		TimeZone:            "UTC",
+		TimezoneOffset:      0,
+	}
+}
+
+func codes(found []models.Inconsistency) []string {
+	var result []string
+	for _, f := range found {
+		result = append(result, f.Code)
+	}
+	return result
+}
+
+func TestDetect(t *testing.T) {
+	checker := NewChecker(DefaultChecks())
+
+	direct3D := macSignals()
+	direct3D.WebGLRenderer = "ANGLE (NVIDIA, NVIDIA GeForce RTX 3080 Direct3D11 vs_5_0 ps_5_0)"
+
+	iphone := models.Signals{
+		UserAgent:           iphoneUA,
+		Platform:            "iPhone",
+		HardwareConcurrency: 32,
+		MaxTouchPoints:      5,
+	}
+
+	screen := macSignals()
+	screen.AvailHeight = 2000
+
+	wrongOffset := macSignals()
+	wrongOffset.TimeZone = "Asia/Tokyo" // UTC+9, reported as -540
+	wrongOffset.TimezoneOffset = 300
+
+	platform := macSignals()
+	platform.Platform = "Win32"
+	platform.WebGLRenderer = ""
+
+	tests := []struct {
+		name     string
+		signals  models.Signals
+		expected []string
+	}{
+		{"consistent mac", macSignals(), nil},
+		{"mac with direct3d", direct3D, []string{CodeMacDirect3D}},
+		{"iphone with 32 cores", iphone, []string{CodeIOSCoreCount}},
+		{"screen smaller than avail", screen, []string{CodeScreenSmallerThanAvail}},
+		{"timezone offset", wrongOffset, []string{CodeTimezoneOffsetMismatch}},
+		{"windows platform on mac ua", platform, []string{CodePlatformUAMismatch}},
+	}
+
+	for _, tt := range tests {
+		if got := codes(checker.Detect(tt.signals)); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestDetect_TimezoneDST(t *testing.T) {
+	checker := NewChecker(DefaultChecks())
+
+	// New York is UTC-5 in winter and UTC-4 in summer; both are plausible.
+	for _, offset := range []int{300, 240} {
+		signals := macSignals()
+		signals.TimeZone = "America/New_York"
+		signals.TimezoneOffset = offset
+
+		if found := checker.Detect(signals); len(found) != 0 {
+			t.Errorf("Offset %d flagged for America/New_York: %v", offset, codes(found))
+		}
+	}
+}
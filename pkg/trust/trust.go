@@ -0,0 +1,57 @@
+// Package trust scores how much a visitor's history can be trusted, from 0
+// (certainly automated or abusive) to 1 (long-lived, consistent and clean).
+package trust
+
+import (
+	"math"
+	"time"
+)
+
+// Inputs aggregate a visitor's identification history.
+type Inputs struct {
+	VisitorAge      time.Duration
+	VisitCount      int
+	Identifications int // Identifications considered for the ratios below
+	BotCount        int // Suspected or bad bot verdicts
+	FlaggedCount    int // Identifications with velocity risk flags
+	TamperCount     int // Identifications with tampering or spoofing findings
+	HardwareHashes  int // Distinct hardware hashes observed, excluding budget-cleared ones
+}
+
+const (
+	// NewVisitorScore is the score of a clean visitor seen exactly once.
+	NewVisitorScore = 0.5
+
+	tenureHorizon      = 90 * 24 * time.Hour
+	familiarityHorizon = 50
+)
+
+// Score combines the inputs. Bot verdicts dominate: a visitor whose every
+// identification was a bot scores 0 regardless of tenure. Among humans,
+// consistency contributes half the score and tenure and visit count a
+// quarter each; risk and tamper findings scale the result down.
+func Score(in Inputs) float64 {
+	if in.Identifications == 0 {
+		return NewVisitorScore
+	}
+	total := float64(in.Identifications)
+
+	humanity := 1 - float64(in.BotCount)/total
+	cleanliness := clamp(1 - 0.5*float64(in.FlaggedCount)/total - 0.5*float64(in.TamperCount)/total)
+
+	consistency := 1.0
+	if in.HardwareHashes > 1 {
+		consistency = 1 / math.Sqrt(float64(in.HardwareHashes))
+	}
+
+	days := in.VisitorAge.Hours() / 24
+	tenure := math.Min(1, math.Log1p(math.Max(days, 0))/math.Log1p(tenureHorizon.Hours()/24))
+	familiarity := math.Min(1, math.Log1p(float64(max(in.VisitCount-1, 0)))/math.Log1p(familiarityHorizon))
+
+	score := humanity * humanity * cleanliness * (0.5*consistency + 0.25*tenure + 0.25*familiarity)
+	return math.Round(clamp(score)*1000) / 1000
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,42 @@
+package trust
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       Inputs
+		min, max float64
+	}{
+		{"new visitor", Inputs{VisitCount: 1, Identifications: 1, HardwareHashes: 1}, 0.5, 0.5},
+		{"loyal visitor", Inputs{VisitorAge: 120 * 24 * time.Hour, VisitCount: 80, Identifications: 80, HardwareHashes: 1}, 1, 1},
+		{"always a bot", Inputs{VisitorAge: 120 * 24 * time.Hour, VisitCount: 80, Identifications: 80, BotCount: 80, HardwareHashes: 1}, 0, 0},
+		{"velocity flagged", Inputs{VisitCount: 10, Identifications: 10, FlaggedCount: 10, HardwareHashes: 1}, 0.2, 0.35},
+		{"shifting hardware", Inputs{VisitCount: 1, Identifications: 4, HardwareHashes: 4}, 0.25, 0.25},
+	}
+
+	for _, tt := range tests {
+		score := Score(tt.in)
+		if score < tt.min || score > tt.max {
+			t.Errorf("%s: Score() = %.3f, want between %.3f and %.3f", tt.name, score, tt.min, tt.max)
+		}
+	}
+}
+
+func TestScore_MonotonicInBots(t *testing.T) {
+	base := Inputs{VisitorAge: 30 * 24 * time.Hour, VisitCount: 20, Identifications: 20, HardwareHashes: 1}
+
+	previous := Score(base)
+	for bots := 1; bots <= 20; bots++ {
+		in := base
+		in.BotCount = bots
+		score := Score(in)
+		if score > previous {
+			t.Fatalf("Score increased from %.3f to %.3f when bot count rose to %d", previous, score, bots)
+		}
+		previous = score
+	}
+}
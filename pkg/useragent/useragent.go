@@ -0,0 +1,117 @@
+// Package useragent extracts coarse browser and OS information from
+// User-Agent strings. It deliberately recognizes only the major families.
+package useragent
+
+import (
+	"strings"
+)
+
+// Info is the parsed form of a User-Agent string.
+type Info struct {
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+	OS             string `json:"os"`
+	Mobile         bool   `json:"mobile"`
+}
+
+// Browser families.
+const (
+	Chrome  = "Chrome"
+	Edge    = "Edge"
+	Opera   = "Opera"
+	Samsung = "Samsung Internet"
+	Firefox = "Firefox"
+	Safari  = "Safari"
+	Unknown = "Unknown"
+)
+
+// OS families.
+const (
+	Windows  = "Windows"
+	MacOS    = "macOS"
+	IOS      = "iOS"
+	Android  = "Android"
+	ChromeOS = "Chrome OS"
+	Linux    = "Linux"
+)
+
+// browserTokens is checked in order; Chromium derivatives must precede Chrome
+// and everything must precede Safari, which most UAs also mention.
+var browserTokens = []struct {
+	token  string
+	family string
+}{
+	{"edg/", Edge},
+	{"edga/", Edge},
+	{"edgios/", Edge},
+	{"opr/", Opera},
+	{"samsungbrowser/", Samsung},
+	{"firefox/", Firefox},
+	{"fxios/", Firefox},
+	{"crios/", Chrome},
+	{"chrome/", Chrome},
+	{"version/", Safari},
+}
+
+// Parse returns the browser family, major version and OS of a User-Agent.
+func Parse(ua string) Info {
+	lower := strings.ToLower(ua)
+	info := Info{Browser: Unknown, OS: Unknown}
+
+	for _, b := range browserTokens {
+		idx := strings.Index(lower, b.token)
+		if idx == -1 {
+			continue
+		}
+		if b.family == Safari && !strings.Contains(lower, "safari/") {
+			continue
+		}
+		info.Browser = b.family
+		info.BrowserVersion = majorVersion(lower[idx+len(b.token):])
+		break
+	}
+
+	switch {
+	case strings.Contains(lower, "windows"):
+		info.OS = Windows
+	case strings.Contains(lower, "iphone"), strings.Contains(lower, "ipad"), strings.Contains(lower, "ipod"):
+		info.OS = IOS
+	case strings.Contains(lower, "android"):
+		info.OS = Android
+	case strings.Contains(lower, "cros"):
+		info.OS = ChromeOS
+	case strings.Contains(lower, "mac os x"), strings.Contains(lower, "macintosh"):
+		info.OS = MacOS
+	case strings.Contains(lower, "linux"):
+		info.OS = Linux
+	}
+
+	info.Mobile = strings.Contains(lower, "mobile") || info.OS == IOS
+	return info
+}
+
+// IsChromium reports whether the browser is built on Chromium.
+func (i Info) IsChromium() bool {
+	switch i.Browser {
+	case Chrome, Edge, Opera, Samsung:
+		return true
+	}
+	return false
+}
+
+// String renders the info as "Browser Version (OS)".
+func (i Info) String() string {
+	browser := i.Browser
+	if i.BrowserVersion != "" {
+		browser += " " + i.BrowserVersion
+	}
+	return browser + " (" + i.OS + ")"
+}
+
+func majorVersion(s string) string {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
@@ -0,0 +1,51 @@
+package useragent
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ua       string
+		expected Info
+	}{
+		{
+			ua:       "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			expected: Info{Browser: Chrome, BrowserVersion: "120", OS: Windows},
+		},
+		{
+			ua:       "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			expected: Info{Browser: Edge, BrowserVersion: "120", OS: Windows},
+		},
+		{
+			ua:       "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:121.0) Gecko/20100101 Firefox/121.0",
+			expected: Info{Browser: Firefox, BrowserVersion: "121", OS: MacOS},
+		},
+		{
+			ua:       "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+			expected: Info{Browser: Safari, BrowserVersion: "17", OS: IOS, Mobile: true},
+		},
+		{
+			ua:       "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36",
+			expected: Info{Browser: Chrome, BrowserVersion: "120", OS: Android, Mobile: true},
+		},
+		{
+			ua:       "",
+			expected: Info{Browser: Unknown, OS: Unknown},
+		},
+	}
+
+	for _, tt := range tests {
+		result := Parse(tt.ua)
+		if result != tt.expected {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.ua, result, tt.expected)
+		}
+	}
+}
+
+func TestIsChromium(t *testing.T) {
+	if !(Info{Browser: Edge}).IsChromium() {
+		t.Error("Edge should be Chromium-based")
+	}
+	if (Info{Browser: Firefox}).IsChromium() || (Info{Browser: Safari}).IsChromium() {
+		t.Error("Firefox and Safari should not be Chromium-based")
+	}
+}
@@ -1,7 +1,9 @@
 package validator
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"regexp"
 	"strings"
 
@@ -10,6 +12,14 @@ import (
 
 var (
 	hashRegex = regexp.MustCompile(`^[a-fA-F0-9]{8,128}$`)
+	uuidRegex = regexp.MustCompile(`^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}$`)
+)
+
+// Request metadata bounds.
+const (
+	MaxTagBytes      = 16 * 1024
+	MaxURLLength     = 2048
+	MaxLinkedIDBytes = 256
 )
 
 type ValidationError struct {
@@ -70,12 +80,52 @@ func ValidateIdentifyRequest(req models.IdentifyRequest) error {
 		v.AddError("user_agent", "too long")
 	}
 
+	switch req.Consent {
+	case "", models.ConsentNone, models.ConsentAnalytics, models.ConsentFull:
+	default:
+		v.AddError("consent", "must be none, analytics or full")
+	}
+	if req.SessionID != "" && !uuidRegex.MatchString(req.SessionID) {
+		v.AddError("session_id", "invalid format")
+	}
+
+	validateMetadata(v, req)
+
 	if !v.IsValid() {
 		return fmt.Errorf("validation failed: %v", v.ErrorMap())
 	}
 	return nil
 }
 
+func validateMetadata(v *Validator, req models.IdentifyRequest) {
+	if len(req.Tag) > 0 {
+		tagJSON, err := json.Marshal(req.Tag)
+		switch {
+		case err != nil:
+			v.AddError("tag", "must be JSON-serializable")
+		case len(tagJSON) > MaxTagBytes:
+			v.AddError("tag", "too large")
+		}
+	}
+
+	if len(req.LinkedID) > MaxLinkedIDBytes {
+		v.AddError("linked_id", "too long")
+	}
+
+	for field, raw := range map[string]string{"url": req.URL, "referrer": req.Referrer} {
+		if raw == "" {
+			continue
+		}
+		if len(raw) > MaxURLLength {
+			v.AddError(field, "too long")
+			continue
+		}
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" || u.Host == "" {
+			v.AddError(field, "invalid URL")
+		}
+	}
+}
+
 func SanitizeString(s string) string {
 	s = strings.ReplaceAll(s, "\x00", "")
 	var result strings.Builder